@@ -2,9 +2,7 @@ package goregression
 
 import (
 	"encoding/json"
-	"fmt"
 	"math"
-	"regexp"
 	"strconv"
 )
 
@@ -27,31 +25,30 @@ func Linear(slope float64) Activation {
 	return Activation{
 		Activate:   func(f float64) float64 { return slope * f },
 		Derivative: func(f float64) float64 { return slope },
-		Show:       func() string { return fmt.Sprintf("Linear(%f)", slope) },
+		Show:       func() string { return "Linear(" + formatFloat(slope) + ")" },
 	}
 }
 
-var linearPattern = regexp.MustCompile(`Linear\(([.0-9eE+-]*)\)`)
-
 func Strech(factor float64, General Activation) Activation {
 	return Activation{
 		Activate:   func(f float64) float64 { return General.Activate(factor * f) },
 		Derivative: func(f float64) float64 { return factor * General.Derivative(factor*f) },
-		Show:       func() string { return fmt.Sprintf("Strech(%f, %s)", factor, General.Show()) },
+		Show:       func() string { return "Strech(" + formatFloat(factor) + ", " + General.Show() + ")" },
 	}
 }
 
-var strechPattern = regexp.MustCompile(`Strech\(([.0-9eE+-]*), (.*)\)`)
-
 func Scale(factor float64, General Activation) Activation {
 	return Activation{
 		Activate:   func(f float64) float64 { return factor * General.Activate(f) },
 		Derivative: func(f float64) float64 { return factor * General.Derivative(f) },
-		Show:       func() string { return fmt.Sprintf("Scale(%f, %s)", factor, General.Show()) },
+		Show:       func() string { return "Scale(" + formatFloat(factor) + ", " + General.Show() + ")" },
 	}
 }
 
-var scalePattern = regexp.MustCompile(`Scale\(([.0-9eE+-]*), (.*)\)`)
+// formatFloat is the shortest representation that parses back to exactly f.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
 
 func sigmoid(f float64) float64 {
 	return 1 / (1 + math.Exp(-f))
@@ -101,6 +98,111 @@ var BiLn = Activation{
 	Show: func() string { return "BiLn" },
 }
 
+func LeakyReLU(alpha float64) Activation {
+	return Activation{
+		Activate: func(f float64) float64 {
+			if f > 0 {
+				return f
+			}
+			return alpha * f
+		},
+		Derivative: func(f float64) float64 {
+			if f > 0 {
+				return 1
+			}
+			return alpha
+		},
+		Show: func() string { return "LeakyReLU(" + formatFloat(alpha) + ")" },
+	}
+}
+
+func ELU(alpha float64) Activation {
+	return Activation{
+		Activate: func(f float64) float64 {
+			if f > 0 {
+				return f
+			}
+			return alpha * math.Expm1(f)
+		},
+		Derivative: func(f float64) float64 {
+			if f > 0 {
+				return 1
+			}
+			return alpha * math.Exp(f)
+		},
+		Show: func() string { return "ELU(" + formatFloat(alpha) + ")" },
+	}
+}
+
+const (
+	seluAlpha = 1.6732632423543772848170429916717
+	seluScale = 1.0507009873554804934193349852946
+)
+
+var SELU = Activation{
+	Activate: func(f float64) float64 {
+		if f > 0 {
+			return seluScale * f
+		}
+		return seluScale * seluAlpha * math.Expm1(f)
+	},
+	Derivative: func(f float64) float64 {
+		if f > 0 {
+			return seluScale
+		}
+		return seluScale * seluAlpha * math.Exp(f)
+	},
+	Show: func() string { return "SELU" },
+}
+
+// GELU uses the exact Gaussian CDF rather than the tanh approximation.
+var GELU = Activation{
+	Activate: func(f float64) float64 {
+		return 0.5 * f * (1 + math.Erf(f/math.Sqrt2))
+	},
+	Derivative: func(f float64) float64 {
+		cdf := 0.5 * (1 + math.Erf(f/math.Sqrt2))
+		pdf := math.Exp(-f*f/2) / math.Sqrt(2*math.Pi)
+		return cdf + f*pdf
+	},
+	Show: func() string { return "GELU" },
+}
+
+func softplus(f float64) float64 {
+	if f > 0 {
+		return f + math.Log1p(math.Exp(-f))
+	}
+	return math.Log1p(math.Exp(f))
+}
+
+var Softplus = Activation{
+	Activate:   softplus,
+	Derivative: sigmoid,
+	Show:       func() string { return "Softplus" },
+}
+
+var Swish = Activation{
+	Activate: func(f float64) float64 {
+		return f * sigmoid(f)
+	},
+	Derivative: func(f float64) float64 {
+		s := sigmoid(f)
+		return s + f*s*(1-s)
+	},
+	Show: func() string { return "Swish" },
+}
+
+var Mish = Activation{
+	Activate: func(f float64) float64 {
+		return f * math.Tanh(softplus(f))
+	},
+	Derivative: func(f float64) float64 {
+		t := math.Tanh(softplus(f))
+		return t + f*(1-t*t)*sigmoid(f)
+	},
+	Show: func() string { return "Mish" },
+}
+
 func (a Activation) String() string {
 	return a.Show()
 }
@@ -120,44 +222,6 @@ func (a *Activation) UnmarshalJSON(text []byte) error {
 }
 
 func matchActivation(name string) (Activation, error) {
-	switch name {
-	case "Tanh":
-		return Tanh, nil
-	case "Sigmoid":
-		return Sigmoid, nil
-	case "BiLn":
-		return BiLn, nil
-	case "ReLU":
-		return ReLU, nil
-	}
-	if match := linearPattern.FindStringSubmatch(name); len(match) == 2 {
-		slope, err := strconv.ParseFloat(match[1], 64)
-		if err != nil {
-			return Activation{}, err
-		}
-		return Linear(slope), nil
-	}
-	if match := scalePattern.FindStringSubmatch(name); len(match) == 3 {
-		factor, err := strconv.ParseFloat(match[1], 64)
-		if err != nil {
-			return Activation{}, err
-		}
-		subAc, err := matchActivation(match[2])
-		if err != nil {
-			return Activation{}, err
-		}
-		return Scale(factor, subAc), nil
-	}
-	if match := strechPattern.FindStringSubmatch(name); len(match) == 3 {
-		factor, err := strconv.ParseFloat(match[1], 64)
-		if err != nil {
-			return Activation{}, err
-		}
-		subAc, err := matchActivation(match[2])
-		if err != nil {
-			return Activation{}, err
-		}
-		return Strech(factor, subAc), nil
-	}
-	return Activation{}, fmt.Errorf("unrecognized Activation: %s", name)
+	p := expressionParser{text: name}
+	return p.parse()
 }
@@ -0,0 +1,71 @@
+package goregression
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+var allActivations = []Activation{
+	Tanh,
+	Sigmoid,
+	ReLU,
+	BiLn,
+	Linear(0.5),
+	Scale(2, Tanh),
+	Strech(3, Sigmoid),
+	LeakyReLU(0.01),
+	ELU(1.5),
+	SELU,
+	GELU,
+	Softplus,
+	Swish,
+	Mish,
+}
+
+func TestDerivatives(t *testing.T) {
+	const h = 1e-6
+	for _, a := range allActivations {
+		// points chosen away from the kink at 0 of the piecewise activations
+		for _, x := range []float64{-7, -2.5, -0.3, 0.2, 1.1, 4} {
+			numeric := (a.Activate(x+h) - a.Activate(x-h)) / (2 * h)
+			analytic := a.Derivative(x)
+			if math.Abs(numeric-analytic) > 1e-6*max(1, math.Abs(analytic)) {
+				t.Errorf("%s'(%v) = %v, numeric estimate %v", a, x, analytic, numeric)
+			}
+		}
+	}
+}
+
+func TestActivationJSON(t *testing.T) {
+	for _, a := range allActivations {
+		text, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loaded Activation
+		if err := json.Unmarshal(text, &loaded); err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		if loaded.Show() != a.Show() {
+			t.Errorf("got %s, want %s", loaded, a)
+		}
+		for _, x := range []float64{-1.5, 0, 2} {
+			if loaded.Activate(x) != a.Activate(x) || loaded.Derivative(x) != a.Derivative(x) {
+				t.Errorf("%s differs after round trip at %v", a, x)
+			}
+		}
+	}
+}
+
+func TestSoftplusStable(t *testing.T) {
+	if got := Softplus.Activate(800); got != 800 {
+		t.Errorf("Softplus(800) = %v, want 800", got)
+	}
+	if got := Softplus.Activate(-800); got != 0 {
+		t.Errorf("Softplus(-800) = %v, want 0", got)
+	}
+	if got := Mish.Activate(800); got != 800 {
+		t.Errorf("Mish(800) = %v, want 800", got)
+	}
+}
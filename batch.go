@@ -0,0 +1,185 @@
+package goregression
+
+import "gonum.org/v1/gonum/mat"
+
+// batchState holds the per-layer matrices of a batched forward and backward
+// pass, one column per sample so every layer is a single W·X product. They
+// are allocated for the largest batch seen and sliced down for smaller ones.
+type batchState struct {
+	cols int
+	// nodes[l] is the input of layer l with a trailing row of ones, the last
+	// entry is the output of the Model.
+	nodes []*mat.Dense
+	// pre[l] is the output of layer l before its activation.
+	pre []*mat.Dense
+	// deltas[l] is the gradient of the error with respect to pre[l].
+	deltas []*mat.Dense
+	// back[l] is deltas[l] multiplied back through layer l.
+	back    []*mat.Dense
+	grads   []*mat.Dense
+	outgrad []float64
+}
+
+// init allocates the matrices for batches of up to cols samples, and the
+// ones used by the backward pass when training is set.
+func (b *batchState) init(m *Model, cols int, training bool) {
+	if b.cols >= cols && len(b.pre) == len(m.Weights) && (!training || b.grads != nil) {
+		ok := true
+		for l, w := range m.Weights {
+			r, c := w.Dims()
+			bc, _ := b.nodes[l].Dims()
+			br, _ := b.pre[l].Dims()
+			ok = ok && bc == c && br == r
+		}
+		if ok {
+			return
+		}
+	}
+	cols = max(cols, b.cols)
+	*b = batchState{cols: cols}
+	for _, w := range m.Weights {
+		r, c := w.Dims()
+		nodes := mat.NewDense(c, cols, nil)
+		for i := 0; i < cols; i++ {
+			nodes.Set(c-1, i, 1)
+		}
+		b.nodes = append(b.nodes, nodes)
+		b.pre = append(b.pre, mat.NewDense(r, cols, nil))
+		if training {
+			b.deltas = append(b.deltas, mat.NewDense(r, cols, nil))
+			b.back = append(b.back, mat.NewDense(c, cols, nil))
+			b.grads = append(b.grads, mat.NewDense(r, c, nil))
+		}
+	}
+	b.nodes = append(b.nodes, mat.NewDense(m.OutputSize(), cols, nil))
+	b.outgrad = make([]float64, m.OutputSize())
+}
+
+// samples returns the first n columns of d.
+func samples(d *mat.Dense, n int) *mat.Dense {
+	r, _ := d.Dims()
+	return d.Slice(0, r, 0, n).(*mat.Dense)
+}
+
+// forward pushes the first n columns of nodes[0] through the Model, with one
+// matrix multiplication per layer.
+func (b *batchState) forward(m *Model, n int) {
+	last := len(m.Weights) - 1
+	for l, w := range m.Weights {
+		pre := samples(b.pre[l], n)
+		pre.Mul(w, samples(b.nodes[l], n))
+		src := pre.RawMatrix()
+		dst := b.nodes[l+1].RawMatrix()
+		if l == last && m.VectorOutput != nil {
+			in := make([]float64, src.Rows)
+			out := make([]float64, src.Rows)
+			for s := 0; s < n; s++ {
+				for i := range in {
+					in[i] = src.Data[i*src.Stride+s]
+				}
+				m.VectorOutput.Activate(out, in)
+				for i, v := range out {
+					dst.Data[i*dst.Stride+s] = v
+				}
+			}
+			continue
+		}
+		activation := m.activation(l)
+		for i := 0; i < src.Rows; i++ {
+			for s, v := range src.Data[i*src.Stride : i*src.Stride+n] {
+				dst.Data[i*dst.Stride+s] = activation.Activate(v)
+			}
+		}
+	}
+}
+
+// PredictBatch predicts every row of inputs and returns the outputs as the
+// rows of a new matrix. Each layer is one matrix product, which gonum spreads
+// over the available cores or hands to a native BLAS registered with
+// blas64.Use. On a single core with gonum's pure Go BLAS the product is slower
+// per operation than the vector products of Predict, so PredictBatch is no
+// faster than calling Predict on every row.
+func (m Model) PredictBatch(inputs *mat.Dense) *mat.Dense {
+	n, c := inputs.Dims()
+	if c != m.InputSize() {
+		panic("incorrect input size")
+	}
+	var b batchState
+	b.init(&m, n, false)
+	b.nodes[0].Slice(0, c, 0, n).(*mat.Dense).Copy(inputs.T())
+	b.forward(&m, n)
+	return mat.DenseCopyOf(b.nodes[len(b.nodes)-1].T())
+}
+
+// matrixGradients is batchGradients for Batched contexts: the whole batch goes
+// through every layer, forwards and backwards, as a single matrix.
+func (tc *TrainingContext) matrixGradients(batch [][]mat.Vector, grads []*mat.Dense) float64 {
+	n := len(batch)
+	b := &tc.batch
+	b.init(tc.Model, n, true)
+	inputs := b.nodes[0]
+	for s, set := range batch {
+		if set[0].Len() != tc.InputSize() {
+			panic("incorrect input size")
+		}
+		for i := 0; i < set[0].Len(); i++ {
+			inputs.Set(i, s, set[0].AtVec(i))
+		}
+	}
+	b.forward(tc.Model, n)
+
+	// gradient of the error with respect to the output layer
+	last := len(tc.Weights) - 1
+	outputsize := tc.OutputSize()
+	out := b.nodes[last+1]
+	pre := b.pre[last]
+	delta := b.deltas[last]
+	loss := tc.loss()
+	activation := tc.activation(last)
+	Error := 0.0
+	for s, set := range batch {
+		final := out.ColView(s)
+		Error += loss.Value(final, set[1])
+		loss.Gradient(b.outgrad, final, set[1])
+		if tc.VectorOutput != nil {
+			if tc.jacobian == nil || tc.jacobian.RawMatrix().Rows != outputsize {
+				tc.jacobian = mat.NewDense(outputsize, outputsize, nil)
+			}
+			tc.VectorOutput.Jacobian(tc.jacobian, mat.Col(nil, s, pre))
+			delta.ColView(s).(*mat.VecDense).MulVec(tc.jacobian.T(), mat.NewVecDense(outputsize, b.outgrad))
+			continue
+		}
+		for node := 0; node < outputsize; node++ {
+			delta.Set(node, s, b.outgrad[node]*activation.Derivative(pre.At(node, s)))
+		}
+	}
+
+	for l := last; l >= 0; l-- {
+		delta := samples(b.deltas[l], n)
+		b.grads[l].Mul(delta, samples(b.nodes[l], n).T())
+		grads[l].Add(grads[l], b.grads[l])
+		if l == 0 {
+			break
+		}
+		back := samples(b.back[l], n)
+		back.Mul(tc.Weights[l].T(), delta)
+		src := back.RawMatrix()
+		pre := b.pre[l-1].RawMatrix()
+		dst := b.deltas[l-1].RawMatrix()
+		activation := tc.activation(l - 1)
+		for i := 0; i < pre.Rows; i++ {
+			for s := 0; s < n; s++ {
+				dst.Data[i*dst.Stride+s] = src.Data[i*src.Stride+s] * activation.Derivative(pre.Data[i*pre.Stride+s])
+			}
+		}
+	}
+	return Error
+}
+
+// gradients picks how the gradients of a batch are summed.
+func (tc *TrainingContext) gradients() func(batch [][]mat.Vector, grads []*mat.Dense) float64 {
+	if tc.Batched {
+		return tc.matrixGradients
+	}
+	return tc.batchGradients
+}
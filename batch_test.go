@@ -0,0 +1,120 @@
+package goregression
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func batchModels() map[string]*Model {
+	source := rand.New(rand.NewPCG(81, 82))
+	softmax := NewModelLayers(source, []Activation{ReLU, GELU, Linear(1)}, 4, 7, 6, 3)
+	softmax.VectorOutput = &Softmax
+	return map[string]*Model{
+		"tanh":    NewModel(source, Tanh, Sigmoid, 4, 8, 3),
+		"softmax": softmax,
+	}
+}
+
+func TestPredictBatch(t *testing.T) {
+	gen := rand.New(rand.NewPCG(83, 84))
+	inputs := mat.NewDense(9, 4, genN(gen, 36))
+	for name, model := range batchModels() {
+		got := model.PredictBatch(inputs)
+		for i := 0; i < 9; i++ {
+			want := model.Predict(inputs.RowView(i))
+			for j := 0; j < want.Len(); j++ {
+				if math.Abs(got.At(i, j)-want.AtVec(j)) > 1e-12 {
+					t.Errorf("%s: row %d output %d: got %v, want %v", name, i, j, got.At(i, j), want.AtVec(j))
+				}
+			}
+		}
+	}
+}
+
+func TestMatrixGradients(t *testing.T) {
+	gen := rand.New(rand.NewPCG(85, 86))
+	set := make([][]mat.Vector, 7)
+	for i := range set {
+		target := make([]float64, 3)
+		target[gen.IntN(3)] = 1
+		set[i] = []mat.Vector{mat.NewVecDense(4, genN(gen, 4)), mat.NewVecDense(3, target)}
+	}
+	for name, model := range batchModels() {
+		loss := MSE
+		if model.VectorOutput != nil {
+			loss = CrossEntropy
+		}
+		train := TrainingContext{Model: model, Loss: loss}
+		want := newGradients(model.Weights)
+		wantErr := train.batchGradients(set, want)
+		got := newGradients(model.Weights)
+		// a larger batch first, so the buffers are sliced down for the second
+		train.matrixGradients(append(set, set...), got)
+		zeroGradients(got)
+		gotErr := train.matrixGradients(set, got)
+		if math.Abs(gotErr-wantErr) > 1e-12 {
+			t.Errorf("%s: error %v, want %v", name, gotErr, wantErr)
+		}
+		for layer := range want {
+			if !mat.EqualApprox(got[layer], want[layer], 1e-12) {
+				t.Errorf("%s: layer %d gradient\n%v\nwant\n%v", name, layer, mat.Formatted(got[layer]), mat.Formatted(want[layer]))
+			}
+		}
+	}
+}
+
+func TestBatchedTraining(t *testing.T) {
+	start := NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1)
+	expect := TrainingContext{Model: start.Clone(), BatchSize: 3}
+	expect.Train(xorSet, 50, 0.5, nil)
+	train := TrainingContext{Model: start.Clone(), BatchSize: 3, Batched: true}
+	train.Train(xorSet, 50, 0.5, nil)
+	for layer, weights := range expect.Weights {
+		if !mat.EqualApprox(weights, train.Weights[layer], 1e-9) {
+			t.Errorf("layer %d: got\n%v\nwant\n%v", layer, mat.Formatted(train.Weights[layer]), mat.Formatted(weights))
+		}
+	}
+}
+
+func BenchmarkPredictBatch(b *testing.B) {
+	gen := rand.New(rand.NewPCG(87, 88))
+	model := NewModel(gen, Tanh, Sigmoid, 64, 256, 256, 10)
+	inputs := mat.NewDense(128, 64, genN(gen, 128*64))
+	b.Run("Predict", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for r := 0; r < 128; r++ {
+				model.Predict(inputs.RowView(r))
+			}
+		}
+	})
+	b.Run("PredictBatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			model.PredictBatch(inputs)
+		}
+	})
+}
+
+func BenchmarkBatchedTraining(b *testing.B) {
+	gen := rand.New(rand.NewPCG(87, 88))
+	set := make([][]mat.Vector, 256)
+	for i := range set {
+		set[i] = []mat.Vector{mat.NewVecDense(64, genN(gen, 64)), mat.NewVecDense(10, genN(gen, 10))}
+	}
+	for _, batched := range []bool{false, true} {
+		b.Run(fmt.Sprintf("batched %v", batched), func(b *testing.B) {
+			train := TrainingContext{
+				Model:     NewModel(rand.New(rand.NewPCG(89, 90)), Tanh, Sigmoid, 64, 256, 256, 10),
+				BatchSize: 64,
+				Batched:   batched,
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				train.Train(set, 1, 0.01, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package goregression
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// Binary layout, all integers little-endian:
+//
+//	magic     [4]byte "GORG"
+//	version   uint16
+//	internal  uint16 length + activation name
+//	output    uint16 length + activation name
+//	per-layer uint32 count + count activation names (version 2)
+//	vector    uint16 length + vector activation name (version 3)
+//	layers    uint32
+//	per layer rows uint32, cols uint32, rows*cols float64 row-major
+//	checksum  uint32 CRC-32 (IEEE) of everything before it
+var binaryMagic = [4]byte{'G', 'O', 'R', 'G'}
+
+const binaryFormatVersion = 3
+
+var (
+	ErrBadMagic = errors.New("goregression: not a binary model")
+	ErrChecksum = errors.New("goregression: binary model checksum mismatch")
+)
+
+func writeName(buf *bytes.Buffer, a Activation) error {
+	name := ""
+	if a.Show != nil {
+		name = a.Show()
+	}
+	if len(name) > math.MaxUint16 {
+		return fmt.Errorf("activation name too long: %d bytes", len(name))
+	}
+	binary.Write(buf, binary.LittleEndian, uint16(len(name)))
+	buf.WriteString(name)
+	return nil
+}
+
+func readName(r io.Reader) (Activation, error) {
+	var n uint16
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return Activation{}, err
+	}
+	name := make([]byte, n)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return Activation{}, err
+	}
+	if n == 0 {
+		return Activation{}, nil
+	}
+	return matchActivation(string(name))
+}
+
+func (m Model) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(binaryMagic[:])
+	binary.Write(&buf, binary.LittleEndian, uint16(binaryFormatVersion))
+	if err := writeName(&buf, m.Internal); err != nil {
+		return nil, err
+	}
+	if err := writeName(&buf, m.Output); err != nil {
+		return nil, err
+	}
+	binary.Write(&buf, binary.LittleEndian, uint32(len(m.Activations)))
+	for _, a := range m.Activations {
+		if err := writeName(&buf, a); err != nil {
+			return nil, err
+		}
+	}
+	vector := ""
+	if m.VectorOutput != nil {
+		vector = m.VectorOutput.Show()
+	}
+	binary.Write(&buf, binary.LittleEndian, uint16(len(vector)))
+	buf.WriteString(vector)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(m.Weights)))
+	for _, weights := range m.Weights {
+		layer := encodeLayer(weights)
+		binary.Write(&buf, binary.LittleEndian, [2]uint32{uint32(layer.Rows), uint32(layer.Cols)})
+		binary.Write(&buf, binary.LittleEndian, layer.Data)
+	}
+	binary.Write(&buf, binary.LittleEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	return buf.Bytes(), nil
+}
+
+func (m *Model) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic)+4 || !bytes.Equal(data[:len(binaryMagic)], binaryMagic[:]) {
+		return ErrBadMagic
+	}
+	body, sum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return ErrChecksum
+	}
+	r := bytes.NewReader(body[len(binaryMagic):])
+	var version uint16
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return fmt.Errorf("reading version: %w", err)
+	}
+	if version < 1 || version > binaryFormatVersion {
+		return fmt.Errorf("unsupported binary model version %d", version)
+	}
+	internal, err := readName(r)
+	if err != nil {
+		return fmt.Errorf("reading internal activation: %w", err)
+	}
+	output, err := readName(r)
+	if err != nil {
+		return fmt.Errorf("reading output activation: %w", err)
+	}
+	var activations []Activation
+	if version >= 2 {
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return fmt.Errorf("reading activation count: %w", err)
+		}
+		if int64(n)*2 > int64(r.Len()) {
+			return fmt.Errorf("%d activations exceed remaining %d bytes", n, r.Len())
+		}
+		for i := 0; i < int(n); i++ {
+			a, err := readName(r)
+			if err != nil {
+				return fmt.Errorf("reading activation %d: %w", i, err)
+			}
+			if a.Show == nil {
+				return fmt.Errorf("activation %d has no name", i)
+			}
+			activations = append(activations, a)
+		}
+	}
+	var vectorOutput *VectorActivation
+	if version >= 3 {
+		var n uint16
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return fmt.Errorf("reading vector activation: %w", err)
+		}
+		name := make([]byte, n)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return fmt.Errorf("reading vector activation: %w", err)
+		}
+		if n > 0 {
+			v, err := matchVectorActivation(string(name))
+			if err != nil {
+				return err
+			}
+			vectorOutput = &v
+		}
+	}
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return fmt.Errorf("reading layer count: %w", err)
+	}
+	layers := make([]encodedLayer, 0, min(int(count), r.Len()/8))
+	for i := 0; i < int(count); i++ {
+		var shape [2]uint32
+		if err := binary.Read(r, binary.LittleEndian, &shape); err != nil {
+			return fmt.Errorf("layer %d: reading shape: %w", i, err)
+		}
+		size := uint64(shape[0]) * uint64(shape[1])
+		if size*8 > uint64(r.Len()) {
+			return fmt.Errorf("layer %d: %dx%d weights exceed remaining %d bytes", i, shape[0], shape[1], r.Len())
+		}
+		layer := encodedLayer{Rows: int(shape[0]), Cols: int(shape[1]), Data: make([]float64, size)}
+		if err := binary.Read(r, binary.LittleEndian, layer.Data); err != nil {
+			return fmt.Errorf("layer %d: reading weights: %w", i, err)
+		}
+		layers = append(layers, layer)
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%d trailing bytes after last layer", r.Len())
+	}
+	weights, err := decodeLayers(layers)
+	if err != nil {
+		return err
+	}
+	if len(activations) > 0 && len(activations) != len(weights) {
+		return fmt.Errorf("model has %d activations for %d layers", len(activations), len(weights))
+	}
+	model := Model{
+		Weights:      weights,
+		Internal:     internal,
+		Output:       output,
+		Activations:  activations,
+		VectorOutput: vectorOutput,
+	}
+	if err := model.Validate(); err != nil {
+		return err
+	}
+	*m = model
+	return nil
+}
@@ -0,0 +1,146 @@
+package goregression
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Metrics describes the progress of training when a Callback is called.
+type Metrics struct {
+	Epoch int
+	// Step counts the updates applied since training started.
+	Step int
+	// TrainLoss is the summed error of the samples in the batch for
+	// OnBatchEnd, and of the whole epoch for OnEpochEnd.
+	TrainLoss float64
+	// ValLoss is the mean loss on the EarlyStopping validation set at the end
+	// of an epoch, NaN when there is none.
+	ValLoss      float64
+	LearningRate float64
+	// GradientNorm is the L2 norm of the update's gradient averaged over its
+	// samples, before any clipping, for OnBatchEnd, and the mean over the
+	// epoch's updates for OnEpochEnd.
+	GradientNorm float64
+	// Elapsed is the time since training started.
+	Elapsed time.Duration
+	Model   *Model
+}
+
+// Callback is notified by the training loops. All calls are made from the
+// goroutine that called the training method.
+type Callback interface {
+	OnEpochStart(Metrics)
+	OnEpochEnd(Metrics)
+	OnBatchEnd(Metrics)
+}
+
+// DivergenceObserver is implemented by Callbacks that want to know when the
+// DivergenceGuard rolls training back. Metrics holds the non-finite loss and
+// the Model after the rollback.
+type DivergenceObserver interface {
+	OnDivergence(Metrics)
+}
+
+// CallbackFuncs is a Callback made of optional functions.
+type CallbackFuncs struct {
+	EpochStart func(Metrics)
+	EpochEnd   func(Metrics)
+	BatchEnd   func(Metrics)
+	Divergence func(Metrics)
+}
+
+func (c CallbackFuncs) OnEpochStart(m Metrics) {
+	if c.EpochStart != nil {
+		c.EpochStart(m)
+	}
+}
+
+func (c CallbackFuncs) OnEpochEnd(m Metrics) {
+	if c.EpochEnd != nil {
+		c.EpochEnd(m)
+	}
+}
+
+func (c CallbackFuncs) OnBatchEnd(m Metrics) {
+	if c.BatchEnd != nil {
+		c.BatchEnd(m)
+	}
+}
+
+func (c CallbackFuncs) OnDivergence(m Metrics) {
+	if c.Divergence != nil {
+		c.Divergence(m)
+	}
+}
+
+// History records the Metrics at the end of every epoch, and of every
+// rollback by the DivergenceGuard.
+type History struct {
+	Epochs      []Metrics
+	Divergences []Metrics
+}
+
+func (h *History) OnEpochStart(Metrics) {}
+
+func (h *History) OnEpochEnd(m Metrics) {
+	h.Epochs = append(h.Epochs, m)
+}
+
+func (h *History) OnBatchEnd(Metrics) {}
+
+func (h *History) OnDivergence(m Metrics) {
+	h.Divergences = append(h.Divergences, m)
+}
+
+type callbackList []Callback
+
+func (tc *TrainingContext) callbacks(extra ...Callback) callbackList {
+	list := append(callbackList(nil), tc.Callbacks...)
+	for _, c := range extra {
+		if c != nil {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+func (l callbackList) OnEpochStart(m Metrics) {
+	for _, c := range l {
+		c.OnEpochStart(m)
+	}
+}
+
+func (l callbackList) OnEpochEnd(m Metrics) {
+	for _, c := range l {
+		c.OnEpochEnd(m)
+	}
+}
+
+func (l callbackList) OnBatchEnd(m Metrics) {
+	for _, c := range l {
+		c.OnBatchEnd(m)
+	}
+}
+
+func (l callbackList) OnDivergence(m Metrics) {
+	for _, c := range l {
+		if o, ok := c.(DivergenceObserver); ok {
+			o.OnDivergence(m)
+		}
+	}
+}
+
+func gradientNorm(grads []*mat.Dense) float64 {
+	sum := 0.0
+	for _, g := range grads {
+		raw := g.RawMatrix()
+		for r := 0; r < raw.Rows; r++ {
+			for _, v := range raw.Data[r*raw.Stride : r*raw.Stride+raw.Cols] {
+				sum += v * v
+			}
+		}
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,129 @@
+package goregression
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Checkpoint is a copy of everything needed to resume training: the Model
+// and the Optimizer with its accumulated state.
+type Checkpoint struct {
+	Model     *Model
+	Optimizer Optimizer
+}
+
+func cloneOptimizer(o Optimizer) Optimizer {
+	if s, ok := o.(stateful); ok {
+		return s.clone()
+	}
+	return o
+}
+
+// Checkpoint copies the current Model and Optimizer. Optimizers from outside
+// this package cannot be copied and are shared with the TrainingContext. In
+// Hogwild mode the Optimizer holds the state of the first worker alone, as of
+// the end of the last epoch.
+func (tc *TrainingContext) Checkpoint() Checkpoint {
+	return Checkpoint{
+		Model:     tc.Model.Clone(),
+		Optimizer: cloneOptimizer(tc.Optimizer),
+	}
+}
+
+// Restore continues training from a copy of c.
+func (tc *TrainingContext) Restore(c Checkpoint) {
+	tc.Model = c.Model.Clone()
+	tc.Optimizer = cloneOptimizer(c.Optimizer)
+}
+
+var optimizers = map[string]func() stateful{
+	"SGD":      func() stateful { return new(SGD) },
+	"Momentum": func() stateful { return new(Momentum) },
+	"Nesterov": func() stateful { return new(Nesterov) },
+	"AdaGrad":  func() stateful { return new(AdaGrad) },
+	"RMSProp":  func() stateful { return new(RMSProp) },
+	"Adam":     func() stateful { return new(Adam) },
+	"AdamW":    func() stateful { return new(AdamW) },
+}
+
+type jsonOptimizer struct {
+	Type   string           `json:"type"`
+	Config json.RawMessage  `json:"config"`
+	Slots  [][]encodedLayer `json:"slots,omitempty"`
+	Steps  []int            `json:"steps,omitempty"`
+}
+
+type jsonCheckpoint struct {
+	Model     *Model         `json:"model"`
+	Optimizer *jsonOptimizer `json:"optimizer,omitempty"`
+}
+
+func (c Checkpoint) MarshalJSON() ([]byte, error) {
+	out := jsonCheckpoint{Model: c.Model}
+	if c.Optimizer != nil {
+		s, ok := c.Optimizer.(stateful)
+		if !ok {
+			return nil, fmt.Errorf("cannot checkpoint optimizer %T", c.Optimizer)
+		}
+		config, err := json.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		state := s.state()
+		out.Optimizer = &jsonOptimizer{
+			Type:   reflect.TypeOf(s).Elem().Name(),
+			Config: config,
+			Slots:  make([][]encodedLayer, len(state.slots)),
+			Steps:  state.steps,
+		}
+		for i, slot := range state.slots {
+			for _, m := range slot {
+				out.Optimizer.Slots[i] = append(out.Optimizer.Slots[i], encodeLayer(m))
+			}
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (c *Checkpoint) UnmarshalJSON(text []byte) error {
+	var in jsonCheckpoint
+	if err := json.Unmarshal(text, &in); err != nil {
+		return err
+	}
+	if in.Model == nil {
+		return fmt.Errorf("checkpoint has no model")
+	}
+	checkpoint := Checkpoint{Model: in.Model}
+	if in.Optimizer != nil {
+		build, ok := optimizers[in.Optimizer.Type]
+		if !ok {
+			return fmt.Errorf("unrecognized optimizer %q", in.Optimizer.Type)
+		}
+		s := build()
+		if err := json.Unmarshal(in.Optimizer.Config, s); err != nil {
+			return fmt.Errorf("optimizer config: %w", err)
+		}
+		state := s.state()
+		state.steps = in.Optimizer.Steps
+		for i, slot := range in.Optimizer.Slots {
+			if len(slot) != len(in.Model.Weights) {
+				return fmt.Errorf("optimizer slot %d has %d layers, model has %d", i, len(slot), len(in.Model.Weights))
+			}
+			layers := make([]*mat.Dense, len(slot))
+			for layer, l := range slot {
+				m, err := l.dense()
+				if err != nil {
+					return fmt.Errorf("optimizer slot %d layer %d: %w", i, layer, err)
+				}
+				layers[layer] = m
+			}
+			state.slots = append(state.slots, layers)
+		}
+		checkpoint.Optimizer = s
+	}
+	*c = checkpoint
+	return nil
+}
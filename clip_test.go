@@ -0,0 +1,85 @@
+package goregression
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestClip(t *testing.T) {
+	grads := func() []*mat.Dense {
+		return []*mat.Dense{mat.NewDense(1, 2, []float64{3, -4}), mat.NewDense(1, 1, []float64{12})}
+	}
+	for _, test := range []struct {
+		tc   TrainingContext
+		want []float64
+	}{
+		{TrainingContext{}, []float64{3, -4, 12}},
+		{TrainingContext{ClipValue: 3.5}, []float64{3, -3.5, 3.5}},
+		{TrainingContext{ClipNorm: 6.5}, []float64{1.5, -2, 6}},
+		{TrainingContext{ClipNorm: 20}, []float64{3, -4, 12}},
+		// value first, then the norm of the clipped gradient
+		{TrainingContext{ClipValue: 4, ClipNorm: 4.5}, []float64{3 * 4.5 / 6.4031242374328485, -4 * 4.5 / 6.4031242374328485, 4 * 4.5 / 6.4031242374328485}},
+	} {
+		g := grads()
+		test.tc.clip(g, gradientNorm(g))
+		got := []float64{g[0].At(0, 0), g[0].At(0, 1), g[1].At(0, 0)}
+		for i := range got {
+			if math.Abs(got[i]-test.want[i]) > 1e-12 {
+				t.Errorf("value %v norm %v: got %v, want %v", test.tc.ClipValue, test.tc.ClipNorm, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestClippedTraining(t *testing.T) {
+	for _, mode := range []string{"Train", "Asynchronous", "Synchronous", "Hogwild"} {
+		// unclipped, y=2x diverges at this rate, see TestDivergenceGuard
+		train := TrainingContext{
+			Model:    NewModel(rand.New(rand.NewPCG(3453, 9988)), Linear(1), Linear(1), 1, 3, 1),
+			ClipNorm: 1,
+		}
+		var clipped bool
+		train.Callbacks = []Callback{CallbackFuncs{BatchEnd: func(m Metrics) {
+			clipped = clipped || m.GradientNorm > train.ClipNorm
+		}}}
+		history, err := trainMode(&train, mode, doubleSet, 300, 1, 0.05)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !clipped {
+			t.Errorf("%s: no gradient norm above ClipNorm was reported", mode)
+		}
+		// clipped steps keep bouncing around the minimum, but stay finite
+		first, final := history.Epochs[0].TrainLoss, history.Epochs[len(history.Epochs)-1].TrainLoss
+		if train.HasNonFinite() || !(final < 1) {
+			t.Errorf("%s: error %v after the first epoch, %v at the end", mode, first, final)
+		}
+	}
+}
+
+func TestChunkedClipMatchesTrain(t *testing.T) {
+	for _, clip := range []TrainingContext{{ClipNorm: 0.5}, {ClipValue: 0.2}} {
+		// one chunk of every sample sums their gradients where Train averages
+		// them, so a quarter of the rate gives the same step
+		want := clip
+		want.Model = NewModel(rand.New(rand.NewPCG(3453, 9988)), Linear(1), Linear(1), 1, 3, 1)
+		want.BatchSize = len(doubleSet)
+		want.Train(doubleSet, 1, 0.04, nil)
+		for _, parallel := range []ParallelMode{Asynchronous, Hogwild} {
+			got := clip
+			got.Model = NewModel(rand.New(rand.NewPCG(3453, 9988)), Linear(1), Linear(1), 1, 3, 1)
+			got.Parallel = parallel
+			got.TrainChunked(doubleSet, 1, 1, len(doubleSet), 0.01, nil)
+			for layer := range want.Weights {
+				if !mat.EqualApprox(got.Weights[layer], want.Weights[layer], 1e-12) {
+					t.Errorf("mode %d, value %v norm %v: layer %d is\n%v\nwant\n%v", parallel, clip.ClipValue, clip.ClipNorm, layer,
+						mat.Formatted(got.Weights[layer]), mat.Formatted(want.Weights[layer]))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package goregression
+
+import (
+	"fmt"
+	"math"
+)
+
+// DivergenceGuard watches training for a loss or weight that is no longer
+// finite. When one appears the Model and Optimizer are rolled back to the
+// start of the latest epoch that finished cleanly, and training resumes from
+// there. The fields after MaxRetries report on the latest training run.
+type DivergenceGuard struct {
+	// HalveRate halves the learning rate at every rollback, for the rest of
+	// training.
+	HalveRate bool
+	// MaxRetries is how many rollbacks are allowed. Past it, training stops
+	// with the last good Model and the context methods return an error
+	// wrapping ErrNonFinite.
+	MaxRetries int
+
+	Rollbacks int
+
+	scale float64
+	// good is where a rollback goes, pending the start of the current epoch
+	good, pending snapshot
+}
+
+type snapshot struct {
+	Checkpoint
+	epoch, step int
+}
+
+func NewDivergenceGuard() *DivergenceGuard {
+	return &DivergenceGuard{HalveRate: true, MaxRetries: 10}
+}
+
+func (g *DivergenceGuard) reset() {
+	g.Rollbacks, g.scale = 0, 1
+	g.good, g.pending = snapshot{}, snapshot{}
+}
+
+// save records the state at the start of an epoch, step updates in. It
+// becomes the rollback point once the epoch ends without diverging.
+func (g *DivergenceGuard) save(tc *TrainingContext, epoch, step int) {
+	g.pending = snapshot{tc.Checkpoint(), epoch, step}
+	if g.good.Model == nil {
+		g.good = g.pending
+	}
+}
+
+func (g *DivergenceGuard) confirm() {
+	g.good = g.pending
+}
+
+// rateScale is what the guard has cut the learning rate to.
+func (g *DivergenceGuard) rateScale() float64 {
+	if g == nil || g.scale == 0 {
+		return 1
+	}
+	return g.scale
+}
+
+// diverged reports whether err or a weight of tc's Model is not finite. It is
+// always false without a guard.
+func (tc *TrainingContext) diverged(err float64) bool {
+	if tc.Divergence == nil {
+		return false
+	}
+	return math.IsNaN(err) || math.IsInf(err, 0) || tc.HasNonFinite()
+}
+
+// rollback puts back the last good state, tells the callbacks about m and
+// returns the epoch and step to continue from. The weights are copied into
+// the current Model, so callers holding it do not keep the diverged ones.
+func (tc *TrainingContext) rollback(callbacks callbackList, m Metrics) (epoch, step int, err error) {
+	g := tc.Divergence
+	g.Rollbacks++
+	tc.Model.copyFrom(g.good.Model)
+	tc.Optimizer = cloneOptimizer(g.good.Optimizer)
+	if g.HalveRate {
+		g.scale /= 2
+	}
+	m.Model = tc.Model
+	callbacks.OnDivergence(m)
+	if g.Rollbacks > g.MaxRetries {
+		err = fmt.Errorf("%w: training diverged %d times", ErrNonFinite, g.Rollbacks)
+	}
+	return g.good.epoch, g.good.step, err
+}
@@ -0,0 +1,93 @@
+package goregression
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var doubleSet = [][]mat.Vector{
+	{mat.NewVecDense(1, []float64{3}), mat.NewVecDense(1, []float64{6})},
+	{mat.NewVecDense(1, []float64{4}), mat.NewVecDense(1, []float64{8})},
+	{mat.NewVecDense(1, []float64{5}), mat.NewVecDense(1, []float64{10})},
+	{mat.NewVecDense(1, []float64{6}), mat.NewVecDense(1, []float64{12})},
+}
+
+// trainMode trains tc on set with Train, or with TrainChunked in the
+// ParallelMode named by mode and chunks of one sample. Hogwild runs are only
+// repeatable with a single worker.
+func trainMode(tc *TrainingContext, mode string, set [][]mat.Vector, iterations int, workers int, lrate float64) (*History, error) {
+	switch mode {
+	case "Train":
+		return tc.TrainContext(context.Background(), set, iterations, lrate)
+	case "Synchronous":
+		tc.Parallel = Synchronous
+	case "Hogwild":
+		tc.Parallel = Hogwild
+	}
+	return tc.TrainChunkedContext(context.Background(), set, iterations, workers, 1, lrate)
+}
+
+func TestDivergenceGuard(t *testing.T) {
+	for _, mode := range []string{"Train", "Asynchronous", "Synchronous", "Hogwild"} {
+		model := NewModel(rand.New(rand.NewPCG(3453, 9988)), Linear(1), Linear(1), 1, 3, 1)
+		train := TrainingContext{
+			Model:      model,
+			Divergence: NewDivergenceGuard(),
+		}
+		// 0.2 diverges at first, halving it a few times converges
+		workers := 1
+		if mode == "Synchronous" {
+			train.BatchSize, workers = 1, 2
+		}
+		history, err := trainMode(&train, mode, doubleSet, 200, workers, 0.2)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if len(history.Divergences) == 0 || len(history.Divergences) != train.Divergence.Rollbacks {
+			t.Errorf("%s: %d divergences recorded, %d rollbacks", mode, len(history.Divergences), train.Divergence.Rollbacks)
+		}
+		// epochs after a rollback are run and recorded again
+		last := history.Epochs[len(history.Epochs)-1]
+		if last.Epoch != 199 || !(last.TrainLoss < 1e-3) {
+			t.Errorf("%s: ended on epoch %d with error %v", mode, last.Epoch, last.TrainLoss)
+		}
+		// Train and Synchronous update the caller's Model in place, rollbacks
+		// included
+		if mode == "Train" || mode == "Synchronous" {
+			if got := model.Predict(mat.NewVecDense(1, []float64{3})).AtVec(0); train.Model != model || !(math.Abs(got-6) < 0.1) {
+				t.Errorf("%s: caller's model predicts %v for 3, want 6", mode, got)
+			}
+		}
+		t.Logf("%s: %d rollbacks, final error %v", mode, train.Divergence.Rollbacks, last.TrainLoss)
+	}
+}
+
+func TestDivergenceGiveUp(t *testing.T) {
+	train := TrainingContext{
+		Model:      NewModel(rand.New(rand.NewPCG(3453, 9988)), Linear(1), Linear(1), 1, 3, 1),
+		Divergence: &DivergenceGuard{MaxRetries: 2},
+	}
+	var rollbacks []Metrics
+	train.Callbacks = []Callback{CallbackFuncs{Divergence: func(m Metrics) { rollbacks = append(rollbacks, m) }}}
+	history, err := train.TrainContext(context.Background(), doubleSet, 50, 1)
+	if !errors.Is(err, ErrNonFinite) {
+		t.Fatalf("got error %v, want %v", err, ErrNonFinite)
+	}
+	if len(rollbacks) != 3 || len(history.Divergences) != 3 {
+		t.Fatalf("%d rollbacks, %d recorded", len(rollbacks), len(history.Divergences))
+	}
+	if train.HasNonFinite() {
+		t.Errorf("left with non-finite weights\n%v", train.Model)
+	}
+	// without halving the same epochs diverge the same way every time
+	for _, m := range rollbacks {
+		if m.LearningRate != 1 || m.Epoch != rollbacks[0].Epoch || m.Step != rollbacks[0].Step {
+			t.Errorf("rollback metrics %+v, first %+v", m, rollbacks[0])
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package goregression
+
+import "gonum.org/v1/gonum/mat"
+
+// EarlyStopping ends training once the loss on Validation has not improved by
+// more than MinDelta for Patience epochs, and puts back the best Model seen.
+// The fields after MinDelta report the outcome of the latest training run.
+type EarlyStopping struct {
+	Validation [][]mat.Vector
+	Patience   int
+	MinDelta   float64
+
+	BestEpoch    int
+	BestScore    float64
+	Stopped      bool
+	StoppedEpoch int
+
+	best *Model
+	wait int
+}
+
+func (e *EarlyStopping) reset() {
+	e.BestEpoch, e.BestScore = -1, 0
+	e.Stopped, e.StoppedEpoch = false, 0
+	e.best, e.wait = nil, 0
+}
+
+// epochEnd records the validation score of the epoch and reports whether
+// training should stop.
+func (e *EarlyStopping) epochEnd(epoch int, tc *TrainingContext) (float64, bool) {
+	score := tc.Evaluate(e.Validation)
+	if e.best == nil || score < e.BestScore-e.MinDelta {
+		e.BestEpoch, e.BestScore = epoch, score
+		e.best = tc.Model.Clone()
+		e.wait = 0
+		return score, false
+	}
+	e.wait++
+	if e.wait >= e.Patience {
+		e.Stopped, e.StoppedEpoch = true, epoch
+		return score, true
+	}
+	return score, false
+}
+
+func (e *EarlyStopping) restore(tc *TrainingContext) {
+	if e.best != nil {
+		tc.Model.copyFrom(e.best)
+		e.best = nil
+	}
+}
+
+// Evaluate is the mean Loss of the Model over a data set.
+func (tc *TrainingContext) Evaluate(set [][]mat.Vector) float64 {
+	if len(set) == 0 {
+		return 0
+	}
+	loss := tc.loss()
+	total := 0.0
+	for _, sample := range set {
+		total += loss.Value(tc.Predict(sample[0]), sample[1])
+	}
+	return total / float64(len(set))
+}
@@ -0,0 +1,53 @@
+package goregression
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestEarlyStopping(t *testing.T) {
+	gen := rand.New(rand.NewPCG(41, 42))
+	sample := func(noise float64) []mat.Vector {
+		x := gen.Float64()*2 - 1
+		return []mat.Vector{mat.NewVecDense(1, []float64{x}), mat.NewVecDense(1, []float64{x + gen.NormFloat64()*noise})}
+	}
+	var trainSet, validation [][]mat.Vector
+	for i := 0; i < 8; i++ {
+		trainSet = append(trainSet, sample(0.3))
+	}
+	for i := 0; i < 50; i++ {
+		validation = append(validation, sample(0))
+	}
+
+	for _, chunked := range []bool{false, true} {
+		stop := &EarlyStopping{Validation: validation, Patience: 25}
+		model := NewModel(rand.New(rand.NewPCG(43, 44)), Tanh, Linear(1), 1, 16, 16, 1)
+		train := TrainingContext{
+			Model:         model,
+			EarlyStopping: stop,
+		}
+		if chunked {
+			train.TrainChunked(trainSet, 5000, 2, 2, 0.02, nil)
+		} else {
+			train.Train(trainSet, 5000, 0.05, nil)
+		}
+		if !stop.Stopped {
+			t.Fatalf("chunked %v: training ran all 5000 epochs", chunked)
+		}
+		t.Logf("chunked %v: best epoch %d, score %f, stopped at %d", chunked, stop.BestEpoch, stop.BestScore, stop.StoppedEpoch)
+		if stop.StoppedEpoch-stop.BestEpoch != stop.Patience {
+			t.Errorf("chunked %v: stopped at %d, %d epochs after the best", chunked, stop.StoppedEpoch, stop.StoppedEpoch-stop.BestEpoch)
+		}
+		if got := train.Evaluate(validation); got != stop.BestScore {
+			t.Errorf("chunked %v: restored model scores %f, best was %f", chunked, got, stop.BestScore)
+		}
+		// Train updates the caller's Model in place, restoring included
+		if !chunked {
+			if got := (&TrainingContext{Model: model}).Evaluate(validation); got != stop.BestScore {
+				t.Errorf("caller's model scores %f, best was %f", got, stop.BestScore)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package goregression
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const modelFormatVersion = 3
+
+type encodedLayer struct {
+	Rows int       `json:"rows"`
+	Cols int       `json:"cols"`
+	Data []float64 `json:"data"`
+}
+
+type jsonModel struct {
+	Version  int            `json:"version"`
+	Internal *Activation    `json:"internal,omitempty"`
+	Output   *Activation    `json:"output,omitempty"`
+	Layers   []encodedLayer `json:"layers"`
+	// added in version 2
+	Activations []Activation `json:"activations,omitempty"`
+	// added in version 3
+	VectorOutput *VectorActivation `json:"vector_output,omitempty"`
+}
+
+func encodeLayer(weights mat.Matrix) encodedLayer {
+	R, C := weights.Dims()
+	layer := encodedLayer{
+		Rows: R,
+		Cols: C,
+		Data: make([]float64, 0, R*C),
+	}
+	for r := 0; r < R; r++ {
+		for c := 0; c < C; c++ {
+			layer.Data = append(layer.Data, weights.At(r, c))
+		}
+	}
+	return layer
+}
+
+func (l encodedLayer) dense() (*mat.Dense, error) {
+	if l.Rows <= 0 || l.Cols <= 0 {
+		return nil, fmt.Errorf("invalid layer shape %dx%d", l.Rows, l.Cols)
+	}
+	if len(l.Data) != l.Rows*l.Cols {
+		return nil, fmt.Errorf("layer %dx%d has %d weights, want %d", l.Rows, l.Cols, len(l.Data), l.Rows*l.Cols)
+	}
+	return mat.NewDense(l.Rows, l.Cols, l.Data), nil
+}
+
+func decodeLayers(layers []encodedLayer) ([]mat.Mutable, error) {
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("model has no layers")
+	}
+	weights := make([]mat.Mutable, len(layers))
+	for i, layer := range layers {
+		w, err := layer.dense()
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %w", i, err)
+		}
+		if i > 0 && layer.Cols != layers[i-1].Rows+1 {
+			return nil, fmt.Errorf("layer %d: has %d columns, previous layer outputs %d nodes (+1 bias)", i, layer.Cols, layers[i-1].Rows)
+		}
+		weights[i] = w
+	}
+	return weights, nil
+}
+
+func (m Model) MarshalJSON() ([]byte, error) {
+	out := jsonModel{
+		Version: modelFormatVersion,
+		Layers:  make([]encodedLayer, len(m.Weights)),
+	}
+	if m.Internal.Show != nil {
+		out.Internal = &m.Internal
+	}
+	if m.Output.Show != nil {
+		out.Output = &m.Output
+	}
+	for i, weights := range m.Weights {
+		out.Layers[i] = encodeLayer(weights)
+	}
+	out.Activations = m.Activations
+	out.VectorOutput = m.VectorOutput
+	return json.Marshal(out)
+}
+
+func (m *Model) UnmarshalJSON(text []byte) error {
+	var in jsonModel
+	if err := json.Unmarshal(text, &in); err != nil {
+		return err
+	}
+	if in.Version < 1 || in.Version > modelFormatVersion {
+		return fmt.Errorf("unsupported model format version %d", in.Version)
+	}
+	weights, err := decodeLayers(in.Layers)
+	if err != nil {
+		return err
+	}
+	if len(in.Activations) > 0 && len(in.Activations) != len(weights) {
+		return fmt.Errorf("model has %d activations for %d layers", len(in.Activations), len(weights))
+	}
+	model := Model{
+		Weights:      weights,
+		Activations:  in.Activations,
+		VectorOutput: in.VectorOutput,
+	}
+	if in.Internal != nil {
+		model.Internal = *in.Internal
+	}
+	if in.Output != nil {
+		model.Output = *in.Output
+	}
+	if err := model.Validate(); err != nil {
+		return err
+	}
+	*m = model
+	return nil
+}
@@ -0,0 +1,148 @@
+package goregression
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func sameWeights(t *testing.T, want, got *Model) {
+	t.Helper()
+	if len(want.Weights) != len(got.Weights) {
+		t.Fatalf("layer count: got %d, want %d", len(got.Weights), len(want.Weights))
+	}
+	for layer, weights := range want.Weights {
+		R, C := weights.Dims()
+		gR, gC := got.Weights[layer].Dims()
+		if R != gR || C != gC {
+			t.Fatalf("layer %d shape: got %dx%d, want %dx%d", layer, gR, gC, R, C)
+		}
+		for r := 0; r < R; r++ {
+			for c := 0; c < C; c++ {
+				if weights.At(r, c) != got.Weights[layer].At(r, c) {
+					t.Fatalf("mismatch [%d][%d][%d]: %v != %v", layer, r, c, got.Weights[layer].At(r, c), weights.At(r, c))
+				}
+			}
+		}
+	}
+}
+
+func TestModelJSON(t *testing.T) {
+	model := NewModel(rand.New(rand.NewPCG(1, 2)), Tanh, Sigmoid, 3, 5, 2)
+	text, err := json.Marshal(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Model
+	if err := json.Unmarshal(text, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	sameWeights(t, model, &loaded)
+	if loaded.Internal.Show() != "Tanh" || loaded.Output.Show() != "Sigmoid" {
+		t.Fatalf("activations not restored: %s, %s", loaded.Internal, loaded.Output)
+	}
+	input := mat.NewVecDense(3, []float64{0.5, -1, 2})
+	want, got := model.Predict(input), loaded.Predict(input)
+	for i := 0; i < want.Len(); i++ {
+		if want.AtVec(i) != got.AtVec(i) {
+			t.Fatalf("prediction %d: got %v, want %v", i, got.AtVec(i), want.AtVec(i))
+		}
+	}
+}
+
+func TestModelJSONErrors(t *testing.T) {
+	for _, text := range []string{
+		`{"version":99,"layers":[{"rows":1,"cols":2,"data":[1,2]}]}`,
+		`{"version":1,"layers":[]}`,
+		`{"version":1,"layers":[{"rows":1,"cols":2,"data":[1]}]}`,
+		`{"version":1,"layers":[{"rows":2,"cols":2,"data":[1,2,3,4]},{"rows":1,"cols":2,"data":[1,2]}]}`,
+		`{"version":1,"output":"Nope","layers":[{"rows":1,"cols":2,"data":[1,2]}]}`,
+		// no activations at all
+		`{"version":1,"layers":[{"rows":1,"cols":2,"data":[1,2]}]}`,
+		`{"version":1,"internal":"Tanh","layers":[{"rows":2,"cols":2,"data":[1,2,3,4]},{"rows":1,"cols":3,"data":[1,2,3]}]}`,
+	} {
+		var m Model
+		if err := json.Unmarshal([]byte(text), &m); err == nil {
+			t.Errorf("expected error for %s", text)
+		}
+	}
+}
+
+func TestModelBinary(t *testing.T) {
+	model := NewModel(rand.New(rand.NewPCG(3, 4)), ReLU, Linear(2), 2, 4, 4, 1)
+	data, err := model.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Model
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	sameWeights(t, model, &loaded)
+	if loaded.Internal.Show() != model.Internal.Show() || loaded.Output.Show() != model.Output.Show() {
+		t.Fatalf("activations not restored: %s, %s", loaded.Internal, loaded.Output)
+	}
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[len(corrupt)/2] ^= 0x10
+	if err := loaded.UnmarshalBinary(corrupt); !errors.Is(err, ErrChecksum) {
+		t.Errorf("corrupted weights: got %v, want %v", err, ErrChecksum)
+	}
+	if err := loaded.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, ErrChecksum) {
+		t.Errorf("truncated data: got %v, want %v", err, ErrChecksum)
+	}
+	if err := loaded.UnmarshalBinary([]byte(`{"version":1}`)); !errors.Is(err, ErrBadMagic) {
+		t.Errorf("json input: got %v, want %v", err, ErrBadMagic)
+	}
+
+	// empty activation names carry a valid checksum but cannot be run
+	for _, broken := range []Model{
+		{Weights: model.Weights},
+		{Weights: model.Weights[:2], Activations: []Activation{ReLU, {}}},
+	} {
+		data, err := broken.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := loaded.UnmarshalBinary(data); err == nil {
+			t.Errorf("no error for activations %v", broken.Activations)
+		}
+	}
+}
+
+func TestPerLayerEncoding(t *testing.T) {
+	model := NewModelLayers(rand.New(rand.NewPCG(5, 6)), []Activation{ReLU, LeakyReLU(0.1), Tanh}, 2, 3, 3, 1)
+	text, err := json.Marshal(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := model.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON, fromBinary Model
+	if err := json.Unmarshal(text, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := fromBinary.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, loaded := range []*Model{&fromJSON, &fromBinary} {
+		sameWeights(t, model, loaded)
+		if len(loaded.Activations) != 3 || loaded.Activations[1].Show() != "LeakyReLU(0.1)" {
+			t.Errorf("activations not restored: %v", loaded.Activations)
+		}
+	}
+
+	// version 1 documents have no per-layer activations
+	var v1 Model
+	if err := json.Unmarshal([]byte(`{"version":1,"internal":"Tanh","output":"Linear(1)","layers":[{"rows":1,"cols":2,"data":[3,1]}]}`), &v1); err != nil {
+		t.Fatal(err)
+	}
+	if got := v1.Predict(mat.NewVecDense(1, []float64{2})).AtVec(0); got != 7 {
+		t.Errorf("version 1 model predicted %v, want 7", got)
+	}
+}
@@ -0,0 +1,137 @@
+package goregression
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	ErrInvalidLayers = errors.New("goregression: invalid layers")
+	ErrNonFinite     = errors.New("goregression: non-finite value")
+)
+
+// ErrDimensionMismatch reports a vector or matrix of the wrong size. What
+// names the offending value.
+type ErrDimensionMismatch struct {
+	What     string
+	Expected int
+	Actual   int
+}
+
+func (e *ErrDimensionMismatch) Error() string {
+	return fmt.Sprintf("goregression: %s has size %d, want %d", e.What, e.Actual, e.Expected)
+}
+
+func checkFinite(what string, v mat.Vector) error {
+	for i := 0; i < v.Len(); i++ {
+		if f := v.AtVec(i); math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("%w: %s[%d] is %v", ErrNonFinite, what, i, f)
+		}
+	}
+	return nil
+}
+
+// NewModelE is NewModel returning ErrInvalidLayers instead of panicking.
+func NewModelE(source *rand.Rand, Internal Activation, Output Activation, layers ...int) (*Model, error) {
+	if len(layers) < 2 {
+		return nil, fmt.Errorf("%w: there should be at least 2 layers: input and output", ErrInvalidLayers)
+	}
+	for i, size := range layers {
+		if size < 1 {
+			return nil, fmt.Errorf("%w: layer %d has %d nodes", ErrInvalidLayers, i, size)
+		}
+	}
+	return NewModel(source, Internal, Output, layers...), nil
+}
+
+// Validate checks that the Model can be run: every layer takes the outputs
+// of the one before plus a bias, there is an Activation for every layer and
+// all weights are finite.
+func (m Model) Validate() error {
+	if len(m.Weights) == 0 {
+		return fmt.Errorf("%w: no layers", ErrInvalidLayers)
+	}
+	if len(m.Activations) > 0 && len(m.Activations) != len(m.Weights) {
+		return fmt.Errorf("%w: %d activations for %d layers", ErrInvalidLayers, len(m.Activations), len(m.Weights))
+	}
+	for layer, weights := range m.Weights {
+		r, c := weights.Dims()
+		if r < 1 || c < 2 {
+			return fmt.Errorf("%w: layer %d is %dx%d", ErrInvalidLayers, layer, r, c)
+		}
+		if layer > 0 {
+			prev, _ := m.Weights[layer-1].Dims()
+			if c != prev+1 {
+				return &ErrDimensionMismatch{What: fmt.Sprintf("layer %d input", layer), Expected: prev + 1, Actual: c}
+			}
+		}
+		if layer < len(m.Weights)-1 || m.VectorOutput == nil {
+			if a := m.activation(layer); a.Activate == nil || a.Derivative == nil {
+				return fmt.Errorf("%w: layer %d has no activation", ErrInvalidLayers, layer)
+			}
+		}
+		for row := 0; row < r; row++ {
+			for col := 0; col < c; col++ {
+				if f := weights.At(row, col); math.IsNaN(f) || math.IsInf(f, 0) {
+					return fmt.Errorf("%w: layer %d weight [%d][%d] is %v", ErrNonFinite, layer, row, col, f)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PredictE is Predict returning an error for inputs of the wrong size and for
+// non-finite inputs or outputs.
+func (m Model) PredictE(input mat.Vector) (mat.Vector, error) {
+	if input.Len() != m.InputSize() {
+		return nil, &ErrDimensionMismatch{What: "input", Expected: m.InputSize(), Actual: input.Len()}
+	}
+	if err := checkFinite("input", input); err != nil {
+		return nil, err
+	}
+	output := m.Predict(input)
+	if err := checkFinite("output", output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// TrainE is Train returning an error, before training, when the Model is not
+// valid or a sample has the wrong sizes or non-finite values, and, after, when
+// training has made a weight non-finite.
+func (tc *TrainingContext) TrainE(trainingSet [][]mat.Vector, iterations int, lrate float64) (*History, error) {
+	if err := tc.Validate(); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(lrate) || math.IsInf(lrate, 0) {
+		return nil, fmt.Errorf("%w: learning rate is %v", ErrNonFinite, lrate)
+	}
+	for i, set := range trainingSet {
+		if len(set) != 2 {
+			return nil, &ErrDimensionMismatch{What: fmt.Sprintf("sample %d", i), Expected: 2, Actual: len(set)}
+		}
+		if set[0].Len() != tc.InputSize() {
+			return nil, &ErrDimensionMismatch{What: fmt.Sprintf("sample %d input", i), Expected: tc.InputSize(), Actual: set[0].Len()}
+		}
+		if !validTarget(tc.loss(), tc.OutputSize(), set[1].Len()) {
+			return nil, &ErrDimensionMismatch{What: fmt.Sprintf("sample %d target", i), Expected: tc.OutputSize(), Actual: set[1].Len()}
+		}
+		if err := checkFinite(fmt.Sprintf("sample %d input", i), set[0]); err != nil {
+			return nil, err
+		}
+		if err := checkFinite(fmt.Sprintf("sample %d target", i), set[1]); err != nil {
+			return nil, err
+		}
+	}
+	history, err := tc.TrainContext(context.Background(), trainingSet, iterations, lrate)
+	if err != nil {
+		return history, err
+	}
+	return history, tc.Validate()
+}
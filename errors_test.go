@@ -0,0 +1,98 @@
+package goregression
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestNewModelE(t *testing.T) {
+	for _, layers := range [][]int{nil, {3}, {3, 0, 1}} {
+		if _, err := NewModelE(rand.New(rand.NewPCG(1, 2)), Tanh, Sigmoid, layers...); !errors.Is(err, ErrInvalidLayers) {
+			t.Errorf("layers %v: got error %v, want %v", layers, err, ErrInvalidLayers)
+		}
+	}
+	model, err := NewModelE(rand.New(rand.NewPCG(1, 2)), Tanh, Sigmoid, 3, 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameWeights(t, NewModel(rand.New(rand.NewPCG(1, 2)), Tanh, Sigmoid, 3, 4, 1), model)
+}
+
+func TestValidate(t *testing.T) {
+	valid := NewModel(rand.New(rand.NewPCG(1, 2)), Tanh, Sigmoid, 3, 4, 2)
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var mismatch *ErrDimensionMismatch
+	broken := valid.Clone()
+	broken.Weights[1] = mat.NewDense(2, 4, nil)
+	if err := broken.Validate(); !errors.As(err, &mismatch) || mismatch.Expected != 5 || mismatch.Actual != 4 {
+		t.Errorf("mismatched layers: got error %v", err)
+	}
+	broken = valid.Clone()
+	broken.Weights[0].Set(1, 2, math.Inf(-1))
+	if err := broken.Validate(); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("infinite weight: got error %v", err)
+	}
+	broken = valid.Clone()
+	broken.Activations = []Activation{Tanh}
+	if err := broken.Validate(); !errors.Is(err, ErrInvalidLayers) {
+		t.Errorf("missing activation: got error %v", err)
+	}
+	if err := (Model{}).Validate(); !errors.Is(err, ErrInvalidLayers) {
+		t.Errorf("empty model: got error %v", err)
+	}
+}
+
+func TestPredictE(t *testing.T) {
+	model := NewModel(rand.New(rand.NewPCG(1, 2)), Tanh, Sigmoid, 3, 4, 2)
+	var mismatch *ErrDimensionMismatch
+	if _, err := model.PredictE(mat.NewVecDense(2, nil)); !errors.As(err, &mismatch) || mismatch.Expected != 3 || mismatch.Actual != 2 {
+		t.Errorf("short input: got error %v", err)
+	}
+	if _, err := model.PredictE(mat.NewVecDense(3, []float64{0, math.NaN(), 0})); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("NaN input: got error %v", err)
+	}
+	input := mat.NewVecDense(3, []float64{1, 2, 3})
+	got, err := model.PredictE(input)
+	if err != nil || !mat.Equal(got, model.Predict(input)) {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
+
+func TestTrainE(t *testing.T) {
+	train := TrainingContext{Model: NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1)}
+	var mismatch *ErrDimensionMismatch
+	bad := [][]mat.Vector{xorSet[0], {mat.NewVecDense(2, nil), mat.NewVecDense(2, nil)}}
+	if _, err := train.TrainE(bad, 1, 0.1); !errors.As(err, &mismatch) || mismatch.What != "sample 1 target" {
+		t.Errorf("wide target: got error %v", err)
+	}
+	bad = [][]mat.Vector{{mat.NewVecDense(2, []float64{math.Inf(1), 0}), mat.NewVecDense(1, nil)}}
+	if _, err := train.TrainE(bad, 1, 0.1); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("infinite input: got error %v", err)
+	}
+	history, err := train.TrainE(xorSet, 3, 0.1)
+	if err != nil || len(history.Epochs) != 3 {
+		t.Errorf("got %v epochs, error %v", history, err)
+	}
+	// Pinball shares a single target between its outputs
+	quantiles := TrainingContext{
+		Model: NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Linear(1), 2, 4, 3),
+		Loss:  Pinball(0.1, 0.5, 0.9),
+	}
+	if _, err := quantiles.TrainE(xorSet, 3, 0.1); err != nil {
+		t.Errorf("pinball: got error %v", err)
+	}
+	bad = [][]mat.Vector{{mat.NewVecDense(2, nil), mat.NewVecDense(2, nil)}}
+	if _, err := quantiles.TrainE(bad, 1, 0.1); !errors.As(err, &mismatch) || mismatch.What != "sample 0 target" {
+		t.Errorf("pinball with 2 targets: got error %v", err)
+	}
+	train.Model = NewModel(rand.New(rand.NewPCG(30, 34)), Linear(1), Linear(1), 2, 4, 1)
+	if _, err := train.TrainE(xorSet, 50, 1e10); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("diverging rate: got error %v", err)
+	}
+}
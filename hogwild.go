@@ -0,0 +1,228 @@
+package goregression
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// sharedWeights holds the weights Hogwild workers update in place. Every
+// weight is stored as float64 bits so it can be read and added to atomically
+// without a lock.
+type sharedWeights struct {
+	layers [][]atomic.Uint64
+}
+
+func newSharedWeights(m *Model) *sharedWeights {
+	s := &sharedWeights{
+		layers: make([][]atomic.Uint64, len(m.Weights)),
+	}
+	for i, w := range m.Weights {
+		R, C := w.Dims()
+		s.layers[i] = make([]atomic.Uint64, R*C)
+		for r := 0; r < R; r++ {
+			for c := 0; c < C; c++ {
+				s.layers[i][r*C+c].Store(math.Float64bits(w.At(r, c)))
+			}
+		}
+	}
+	return s
+}
+
+// load copies the shared weights into m, a Clone of the Model they were
+// made from.
+func (s *sharedWeights) load(m *Model) {
+	for i, layer := range s.layers {
+		data := m.Weights[i].(*mat.Dense).RawMatrix().Data
+		for j := range layer {
+			data[j] = math.Float64frombits(layer[j].Load())
+		}
+	}
+}
+
+// add adds the difference between m and before to the shared weights.
+// Concurrent adds are never lost, but other workers may see any mix of old
+// and new weights.
+func (s *sharedWeights) add(m *Model, before [][]float64) {
+	for i, layer := range s.layers {
+		data := m.Weights[i].(*mat.Dense).RawMatrix().Data
+		for j := range layer {
+			delta := data[j] - before[i][j]
+			if delta == 0 {
+				continue
+			}
+			for {
+				old := layer[j].Load()
+				if layer[j].CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+					break
+				}
+			}
+		}
+	}
+}
+
+type hogwildStep struct {
+	data  [][]mat.Vector
+	lrate float64
+}
+
+// trainHogwild runs the workers against a single shared copy of the weights.
+// Each worker reads the weights, computes the summed gradient of a chunk,
+// steps its own copy of the Optimizer and adds the change back, with no
+// coordination between workers. The Model is copied out of the shared
+// weights at the end of every epoch, once the workers are idle, and the
+// first worker's Optimizer is copied back to tc; the state the other workers
+// accumulated is dropped.
+func (tc *TrainingContext) trainHogwild(ctx context.Context, trainingSet [][]mat.Vector, iterations int, workers int, chunksize int, lrate float64, extra Callback) (*History, error) {
+	if _, ok := tc.optimizer().(stateful); !ok {
+		return nil, fmt.Errorf("cannot copy optimizer %T for each Hogwild worker", tc.Optimizer)
+	}
+	history := new(History)
+	callbacks := tc.callbacks(history, extra)
+	started := time.Now()
+	shared := newSharedWeights(tc.Model)
+	pool := tc.startHogwild(shared, workers)
+	defer func() { pool.stop() }()
+
+	if tc.EarlyStopping != nil {
+		tc.EarlyStopping.reset()
+		defer tc.EarlyStopping.restore(tc)
+	}
+	if tc.Divergence != nil {
+		tc.Divergence.reset()
+	}
+	order := slices.Clone(trainingSet)
+	step, applied := 0, 0
+	var err error
+training:
+	for i := 0; i < iterations; i++ {
+		if tc.Divergence != nil {
+			tc.Divergence.save(tc, i, applied)
+		}
+		callbacks.OnEpochStart(Metrics{Epoch: i, Step: applied, LearningRate: tc.LearningRate, Elapsed: time.Since(started), Model: tc.Model})
+		samples := tc.epochOrder(trainingSet, order)
+		epoch := Metrics{Epoch: i}
+		updates, pending := 0, 0
+		for start := 0; start < len(samples) || pending > 0; {
+			var send chan hogwildStep
+			var next hogwildStep
+			if start < len(samples) {
+				if err = ctx.Err(); err != nil {
+					start = len(samples)
+					continue
+				}
+				send = pool.stepch
+				next = hogwildStep{
+					data:  samples[start:min(start+chunksize, len(samples))],
+					lrate: tc.rate(i, step, lrate),
+				}
+			}
+			select {
+			case send <- next:
+				start += chunksize
+				step++
+				pending++
+			case change := <-pool.changech:
+				pending--
+				applied++
+				updates++
+				epoch.TrainLoss += change.err
+				epoch.GradientNorm += change.norm
+				// the shared weights are still changing, so no Model is given
+				callbacks.OnBatchEnd(Metrics{
+					Epoch:        i,
+					Step:         applied,
+					TrainLoss:    change.err,
+					LearningRate: change.lrate,
+					GradientNorm: change.norm,
+					Elapsed:      time.Since(started),
+				})
+			}
+		}
+		tc.Model = tc.Model.Clone()
+		shared.load(tc.Model)
+		if tc.Optimizer != nil {
+			tc.Optimizer = cloneOptimizer(pool.opts[0])
+		}
+		if err != nil {
+			break training
+		}
+		// the shared weights cannot be checked while they change, so the
+		// guard looks at whole epochs
+		if tc.diverged(epoch.TrainLoss) {
+			pool.stop()
+			i, applied, err = tc.rollback(callbacks, Metrics{
+				Epoch:     i,
+				Step:      applied,
+				TrainLoss: epoch.TrainLoss,
+				Elapsed:   time.Since(started),
+			})
+			step = applied
+			shared = newSharedWeights(tc.Model)
+			pool = tc.startHogwild(shared, workers)
+			if err != nil {
+				break training
+			}
+			i--
+			continue
+		}
+		epoch.Step, epoch.Elapsed = applied, time.Since(started)
+		if tc.epochEnd(callbacks, epoch, updates) {
+			break
+		}
+	}
+	return history, err
+}
+
+type hogwildPool struct {
+	stepch      chan hogwildStep
+	changech    chan update
+	opts        []Optimizer
+	workerGroup sync.WaitGroup
+}
+
+// startHogwild starts workers that update shared, each with its own copy of
+// the Optimizer of tc.
+func (tc *TrainingContext) startHogwild(shared *sharedWeights, workers int) *hogwildPool {
+	pool := &hogwildPool{
+		stepch:   make(chan hogwildStep),
+		changech: make(chan update),
+	}
+	pool.workerGroup.Add(workers)
+	for i := 0; i < workers; i++ {
+		local := &TrainingContext{Model: tc.Model.Clone(), Loss: tc.Loss}
+		before := make([][]float64, len(local.Weights))
+		opt := cloneOptimizer(tc.optimizer())
+		opt.Init(local.Weights)
+		pool.opts = append(pool.opts, opt)
+		go func() {
+			defer pool.workerGroup.Done()
+			grads := newGradients(local.Weights)
+			for step := range pool.stepch {
+				shared.load(local.Model)
+				for layer, w := range local.Weights {
+					before[layer] = append(before[layer][:0], w.(*mat.Dense).RawMatrix().Data...)
+				}
+				zeroGradients(grads)
+				err := local.batchGradients(step.data, grads)
+				err += tc.Regularization.regularize(grads, local.Weights, float64(len(step.data)))
+				norm := tc.clipSum(grads, float64(len(step.data)))
+				applyGradients(opt, local.Weights, grads, step.lrate)
+				shared.add(local.Model, before)
+				pool.changech <- update{lrate: step.lrate, err: err, norm: norm}
+			}
+		}()
+	}
+	return pool
+}
+
+func (pool *hogwildPool) stop() {
+	close(pool.stepch)
+	pool.workerGroup.Wait()
+}
@@ -0,0 +1,167 @@
+package goregression
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Initializer draws the starting weights of a layer. weights has one row per
+// output node and one column per input node, plus a last column of biases.
+// All randomness comes from source, so a seeded source gives the same Model.
+type Initializer interface {
+	Initialize(source *rand.Rand, weights *mat.Dense)
+}
+
+// fans returns the inputs and outputs of a layer, not counting the bias.
+func fans(weights *mat.Dense) (in, out float64) {
+	r, c := weights.Dims()
+	return float64(max(c-1, 1)), float64(r)
+}
+
+// distribution fills every weight, biases included, from a uniform or normal
+// distribution whose spread depends on the fans of the layer.
+type distribution struct {
+	name    string
+	uniform bool
+	// scale is the standard deviation of normal distributions and the
+	// limit of uniform ones.
+	scale func(in, out float64) float64
+}
+
+func (d distribution) Initialize(source *rand.Rand, weights *mat.Dense) {
+	scale := d.scale(fans(weights))
+	raw := weights.RawMatrix()
+	for r := 0; r < raw.Rows; r++ {
+		row := raw.Data[r*raw.Stride : r*raw.Stride+raw.Cols]
+		for c := range row {
+			if d.uniform {
+				row[c] = scale * (2*source.Float64() - 1)
+			} else {
+				row[c] = scale * source.NormFloat64()
+			}
+		}
+	}
+}
+
+func (d distribution) String() string {
+	return d.name
+}
+
+var (
+	// UnitNormal draws from the standard normal distribution, as NewModel does.
+	UnitNormal Initializer = distribution{"UnitNormal", false, func(in, out float64) float64 { return 1 }}
+	// XavierUniform, also called Glorot uniform, keeps the variance of
+	// Tanh and Sigmoid layers steady in both directions.
+	XavierUniform Initializer = distribution{"XavierUniform", true, func(in, out float64) float64 { return math.Sqrt(6 / (in + out)) }}
+	XavierNormal  Initializer = distribution{"XavierNormal", false, func(in, out float64) float64 { return math.Sqrt(2 / (in + out)) }}
+	// HeUniform and HeNormal, also called Kaiming, make up for the half of
+	// the signal ReLU-like activations drop.
+	HeUniform Initializer = distribution{"HeUniform", true, func(in, out float64) float64 { return math.Sqrt(6 / in) }}
+	HeNormal  Initializer = distribution{"HeNormal", false, func(in, out float64) float64 { return math.Sqrt(2 / in) }}
+	// LeCunUniform and LeCunNormal suit self-normalizing SELU layers.
+	LeCunUniform Initializer = distribution{"LeCunUniform", true, func(in, out float64) float64 { return math.Sqrt(3 / in) }}
+	LeCunNormal  Initializer = distribution{"LeCunNormal", false, func(in, out float64) float64 { return math.Sqrt(1 / in) }}
+)
+
+// Orthogonal draws the weights, biases included, as a random matrix with
+// orthonormal rows or columns, whichever there are fewer of, scaled by Gain.
+type Orthogonal struct {
+	Gain float64
+}
+
+func (o Orthogonal) Initialize(source *rand.Rand, weights *mat.Dense) {
+	r, c := weights.Dims()
+	// QR needs at least as many rows as columns, so work on the transpose of
+	// wide layers
+	rows, cols := r, c
+	if r < c {
+		rows, cols = c, r
+	}
+	gaussian := mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			gaussian.Set(i, j, source.NormFloat64())
+		}
+	}
+	var qr mat.QR
+	qr.Factorize(gaussian)
+	var q, rr mat.Dense
+	qr.QTo(&q)
+	qr.RTo(&rr)
+	// flipping columns to match the signs of R's diagonal makes the result
+	// uniformly distributed over orthogonal matrices
+	gain := o.Gain
+	if gain == 0 {
+		gain = 1
+	}
+	for j := 0; j < cols; j++ {
+		sign := gain
+		if rr.At(j, j) < 0 {
+			sign = -gain
+		}
+		for i := 0; i < rows; i++ {
+			if r < c {
+				weights.Set(j, i, sign*q.At(i, j))
+			} else {
+				weights.Set(i, j, sign*q.At(i, j))
+			}
+		}
+	}
+}
+
+// ZeroBias wraps an Initializer and sets every bias to zero.
+type ZeroBias struct {
+	Initializer
+}
+
+func (z ZeroBias) Initialize(source *rand.Rand, weights *mat.Dense) {
+	z.Initializer.Initialize(source, weights)
+	r, c := weights.Dims()
+	for i := 0; i < r; i++ {
+		weights.Set(i, c-1, 0)
+	}
+}
+
+// InitializerFor picks the usual Initializer for a layer using a: He for the
+// ReLU family, LeCun for SELU and Xavier for everything else.
+func InitializerFor(a Activation) Initializer {
+	if a.Show == nil {
+		return XavierUniform
+	}
+	name, _, _ := strings.Cut(a.String(), "(")
+	switch name {
+	case "ReLU", "LeakyReLU", "ELU", "GELU", "Swish", "Mish":
+		return HeNormal
+	case "SELU":
+		return LeCunNormal
+	}
+	return XavierUniform
+}
+
+// NewModelInit is NewModel with the weights drawn by init. When init is nil
+// every layer uses InitializerFor its activation.
+func NewModelInit(source *rand.Rand, init Initializer, Internal Activation, Output Activation, layers ...int) *Model {
+	model := newModelShape(Internal, Output, layers...)
+	model.Initialize(source, init)
+	return model
+}
+
+// Initialize redraws every weight of the Model with init, or with
+// InitializerFor each layer's activation when init is nil.
+func (m *Model) Initialize(source *rand.Rand, init Initializer) {
+	for layer, weights := range m.Weights {
+		dense, ok := weights.(*mat.Dense)
+		if !ok {
+			dense = mat.DenseCopyOf(weights)
+			m.Weights[layer] = dense
+		}
+		layerInit := init
+		if layerInit == nil {
+			layerInit = InitializerFor(m.activation(layer))
+		}
+		layerInit.Initialize(source, dense)
+	}
+}
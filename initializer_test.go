@@ -0,0 +1,109 @@
+package goregression
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestInitializerSpread(t *testing.T) {
+	for _, test := range []struct {
+		init Initializer
+		want float64 // variance for 200 inputs and 100 outputs
+	}{
+		{UnitNormal, 1},
+		{XavierUniform, 2.0 / 300},
+		{XavierNormal, 2.0 / 300},
+		{HeUniform, 2.0 / 200},
+		{HeNormal, 2.0 / 200},
+		{LeCunUniform, 1.0 / 200},
+		{LeCunNormal, 1.0 / 200},
+	} {
+		weights := mat.NewDense(100, 201, nil)
+		test.init.Initialize(rand.New(rand.NewPCG(1, 2)), weights)
+		sum, squares := 0.0, 0.0
+		for _, w := range weights.RawMatrix().Data {
+			sum += w
+			squares += w * w
+		}
+		n := float64(len(weights.RawMatrix().Data))
+		mean, variance := sum/n, squares/n-(sum/n)*(sum/n)
+		if math.Abs(mean) > 0.05*math.Sqrt(test.want) || math.Abs(variance/test.want-1) > 0.05 {
+			t.Errorf("%v: mean %v, variance %v, want 0 and %v", test.init, mean, variance, test.want)
+		}
+	}
+}
+
+func TestOrthogonal(t *testing.T) {
+	for _, shape := range [][2]int{{6, 4}, {3, 8}, {5, 5}} {
+		r, c := shape[0], shape[1]
+		weights := mat.NewDense(r, c, nil)
+		Orthogonal{Gain: 2}.Initialize(rand.New(rand.NewPCG(3, 4)), weights)
+		var product mat.Dense
+		if r >= c {
+			product.Mul(weights.T(), weights)
+		} else {
+			product.Mul(weights, weights.T())
+		}
+		n := min(r, c)
+		identity := mat.NewDiagDense(n, nil)
+		for i := 0; i < n; i++ {
+			identity.SetDiag(i, 4)
+		}
+		if !mat.EqualApprox(&product, identity, 1e-12) {
+			t.Errorf("%dx%d: not orthogonal\n%v", r, c, mat.Formatted(&product))
+		}
+	}
+}
+
+func TestZeroBias(t *testing.T) {
+	weights := mat.NewDense(4, 3, nil)
+	ZeroBias{HeNormal}.Initialize(rand.New(rand.NewPCG(5, 6)), weights)
+	for r := 0; r < 4; r++ {
+		if weights.At(r, 2) != 0 || weights.At(r, 0) == 0 {
+			t.Errorf("row %d: %v", r, weights.RawRowView(r))
+		}
+	}
+}
+
+func TestInitializerFor(t *testing.T) {
+	for _, test := range []struct {
+		activation Activation
+		want       Initializer
+	}{
+		{Tanh, XavierUniform},
+		{Sigmoid, XavierUniform},
+		{Linear(1), XavierUniform},
+		{ReLU, HeNormal},
+		{LeakyReLU(0.1), HeNormal},
+		{GELU, HeNormal},
+		{SELU, LeCunNormal},
+	} {
+		if got := InitializerFor(test.activation); fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("%v: got %v, want %v", test.activation, got, test.want)
+		}
+	}
+}
+
+func TestNewModelInit(t *testing.T) {
+	first := NewModelInit(rand.New(rand.NewPCG(7, 8)), nil, ReLU, Sigmoid, 50, 40, 2)
+	second := NewModelInit(rand.New(rand.NewPCG(7, 8)), nil, ReLU, Sigmoid, 50, 40, 2)
+	sameWeights(t, first, second)
+	// He for the ReLU layer, Xavier for the Sigmoid output
+	if limit := math.Sqrt(6.0 / 42); mat.Max(first.Weights[1]) > limit || mat.Min(first.Weights[1]) < -limit {
+		t.Errorf("output layer outside ±%v", limit)
+	}
+	// He normal has a standard deviation of 0.2 for 50 inputs
+	if hidden := mat.Max(first.Weights[0]); hidden > 1.2 {
+		t.Errorf("hidden layer max %v", hidden)
+	}
+
+	layers := NewModelLayers(rand.New(rand.NewPCG(7, 8)), []Activation{SELU, Linear(1)}, 3, 4, 1)
+	layers.Initialize(rand.New(rand.NewPCG(9, 10)), Orthogonal{})
+	if err := layers.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
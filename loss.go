@@ -0,0 +1,190 @@
+package goregression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Loss measures how far a Model's output is from the target.
+//
+// Value averages the per-output losses, except for the cross-entropies which
+// compare whole distributions. Gradient writes the derivative of each
+// output's own loss term into dst, so it does not shrink with the number of
+// outputs.
+type Loss interface {
+	Value(out, target mat.Vector) float64
+	Gradient(dst []float64, out, target mat.Vector)
+	String() string
+}
+
+// targetSizer is implemented by losses whose targets need not have one value
+// per output.
+type targetSizer interface {
+	validTarget(outputs, target int) bool
+}
+
+// validTarget reports whether loss accepts targets of size target for a Model
+// with the given number of outputs.
+func validTarget(loss Loss, outputs, target int) bool {
+	if s, ok := loss.(targetSizer); ok {
+		return s.validTarget(outputs, target)
+	}
+	return target == outputs
+}
+
+// elementwise adapts a loss that treats every output independently.
+type elementwise struct {
+	name  string
+	value func(out, target float64) float64
+	grad  func(out, target float64) float64
+}
+
+func (e elementwise) Value(out, target mat.Vector) float64 {
+	sum := 0.0
+	for i := 0; i < target.Len(); i++ {
+		sum += e.value(out.AtVec(i), target.AtVec(i))
+	}
+	return sum / float64(target.Len())
+}
+
+func (e elementwise) Gradient(dst []float64, out, target mat.Vector) {
+	for i := range dst {
+		dst[i] = e.grad(out.AtVec(i), target.AtVec(i))
+	}
+}
+
+func (e elementwise) String() string {
+	return e.name
+}
+
+// MSE is half the squared error, the loss Train has always used.
+var MSE Loss = elementwise{
+	name: "MSE",
+	value: func(out, target float64) float64 {
+		diff := target - out
+		return (diff * diff) / 2
+	},
+	grad: func(out, target float64) float64 { return out - target },
+}
+
+var MAE Loss = elementwise{
+	name:  "MAE",
+	value: func(out, target float64) float64 { return math.Abs(out - target) },
+	grad: func(out, target float64) float64 {
+		switch {
+		case out > target:
+			return 1
+		case out < target:
+			return -1
+		}
+		return 0
+	},
+}
+
+// Huber is quadratic within delta of the target and linear beyond it.
+func Huber(delta float64) Loss {
+	return elementwise{
+		name: "Huber(" + formatFloat(delta) + ")",
+		value: func(out, target float64) float64 {
+			diff := math.Abs(out - target)
+			if diff <= delta {
+				return diff * diff / 2
+			}
+			return delta * (diff - delta/2)
+		},
+		grad: func(out, target float64) float64 {
+			return math.Max(-delta, math.Min(delta, out-target))
+		},
+	}
+}
+
+var LogCosh Loss = elementwise{
+	name: "LogCosh",
+	value: func(out, target float64) float64 {
+		// log(cosh(x)) without overflowing cosh for large x
+		x := math.Abs(out - target)
+		return x + math.Log1p(math.Exp(-2*x)) - math.Ln2
+	},
+	grad: func(out, target float64) float64 { return math.Tanh(out - target) },
+}
+
+// probabilityEpsilon keeps log and division finite when an output reaches 0 or 1.
+const probabilityEpsilon = 1e-12
+
+// BinaryCrossEntropy expects outputs in (0, 1), e.g. from Sigmoid.
+var BinaryCrossEntropy Loss = elementwise{
+	name: "BinaryCrossEntropy",
+	value: func(out, target float64) float64 {
+		p := math.Min(math.Max(out, probabilityEpsilon), 1-probabilityEpsilon)
+		return -(target*math.Log(p) + (1-target)*math.Log(1-p))
+	},
+	grad: func(out, target float64) float64 {
+		p := math.Min(math.Max(out, probabilityEpsilon), 1-probabilityEpsilon)
+		return (p - target) / (p * (1 - p))
+	},
+}
+
+// PoissonDeviance expects positive outputs, e.g. from Softplus, and
+// non-negative count targets.
+var PoissonDeviance Loss = elementwise{
+	name: "PoissonDeviance",
+	value: func(out, target float64) float64 {
+		mu := math.Max(out, probabilityEpsilon)
+		dev := mu - target
+		if target > 0 {
+			dev += target * math.Log(target/mu)
+		}
+		return 2 * dev
+	},
+	grad: func(out, target float64) float64 {
+		mu := math.Max(out, probabilityEpsilon)
+		return 2 * (1 - target/mu)
+	},
+}
+
+type crossEntropy struct{}
+
+// CrossEntropy compares a target distribution with output probabilities, as
+// produced by Softmax or Sparsemax.
+var CrossEntropy Loss = crossEntropy{}
+
+func (crossEntropy) Value(out, target mat.Vector) float64 {
+	sum := 0.0
+	for i := 0; i < target.Len(); i++ {
+		if t := target.AtVec(i); t != 0 {
+			sum -= t * math.Log(math.Max(out.AtVec(i), probabilityEpsilon))
+		}
+	}
+	return sum
+}
+
+func (crossEntropy) Gradient(dst []float64, out, target mat.Vector) {
+	for i := range dst {
+		dst[i] = -target.AtVec(i) / math.Max(out.AtVec(i), probabilityEpsilon)
+	}
+}
+
+func (crossEntropy) String() string { return "CrossEntropy" }
+
+type nll struct{}
+
+// NLL is CrossEntropy for outputs that are log-probabilities, as produced by
+// LogSoftmax. It avoids the division by small probabilities.
+var NLL Loss = nll{}
+
+func (nll) Value(out, target mat.Vector) float64 {
+	sum := 0.0
+	for i := 0; i < target.Len(); i++ {
+		sum -= target.AtVec(i) * out.AtVec(i)
+	}
+	return sum
+}
+
+func (nll) Gradient(dst []float64, out, target mat.Vector) {
+	for i := range dst {
+		dst[i] = -target.AtVec(i)
+	}
+}
+
+func (nll) String() string { return "NLL" }
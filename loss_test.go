@@ -0,0 +1,61 @@
+package goregression
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestLossGradients(t *testing.T) {
+	const h = 1e-6
+	out := []float64{0.2, 0.7, 0.9, 0.05}
+	target := []float64{0, 1, 0.5, 0.4}
+	for _, loss := range []Loss{MSE, MAE, Huber(0.5), LogCosh, BinaryCrossEntropy, PoissonDeviance, CrossEntropy, NLL, Pinball(0.1, 0.3, 0.6, 0.9)} {
+		grad := make([]float64, len(out))
+		loss.Gradient(grad, mat.NewVecDense(len(out), out), mat.NewVecDense(len(target), target))
+		for i := range out {
+			up := append([]float64(nil), out...)
+			down := append([]float64(nil), out...)
+			up[i] += h
+			down[i] -= h
+			numeric := (loss.Value(mat.NewVecDense(len(up), up), mat.NewVecDense(len(target), target)) -
+				loss.Value(mat.NewVecDense(len(down), down), mat.NewVecDense(len(target), target))) / (2 * h)
+			if name := loss.String(); name != "CrossEntropy" && name != "NLL" {
+				// Value averages over outputs, Gradient does not
+				numeric *= float64(len(out))
+			}
+			if math.Abs(numeric-grad[i]) > 1e-5*max(1, math.Abs(grad[i])) {
+				t.Errorf("%s gradient[%d] = %v, numeric estimate %v", loss, i, grad[i], numeric)
+			}
+		}
+	}
+}
+
+func TestHuberResistsOutliers(t *testing.T) {
+	var data [][]mat.Vector
+	for x := 0.0; x < 1; x += 0.1 {
+		y := 2 * x
+		if x > 0.85 {
+			y = 40
+		}
+		data = append(data, []mat.Vector{mat.NewVecDense(1, []float64{x}), mat.NewVecDense(1, []float64{y})})
+	}
+	slope := func(loss Loss) float64 {
+		train := TrainingContext{
+			Model: NewModel(rand.New(rand.NewPCG(1, 2)), Linear(1), Linear(1), 1, 1),
+			Loss:  loss,
+		}
+		train.Train(data, 2000, 0.05, nil)
+		return train.Weights[0].At(0, 0)
+	}
+	mse, huber := slope(MSE), slope(Huber(0.1))
+	t.Logf("slope with MSE %f, with Huber %f", mse, huber)
+	if math.Abs(huber-2) > 0.5 {
+		t.Errorf("Huber slope %f should be close to 2", huber)
+	}
+	if math.Abs(mse-2) < math.Abs(huber-2) {
+		t.Errorf("MSE slope %f should be pulled further by the outlier than Huber %f", mse, huber)
+	}
+}
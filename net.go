@@ -1,12 +1,15 @@
 package goregression
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -15,9 +18,20 @@ type Model struct {
 	Weights  []mat.Mutable
 	Internal Activation
 	Output   Activation
+	// Activations, when set, holds one Activation per layer of Weights and
+	// takes the place of Internal and Output.
+	Activations []Activation
+	// VectorOutput, when set, replaces the scalar Activation of the output
+	// layer, e.g. Softmax for classification heads.
+	VectorOutput *VectorActivation
 }
 
 func NewModel(source *rand.Rand, Internal Activation, Output Activation, layers ...int) *Model {
+	return NewModelInit(source, UnitNormal, Internal, Output, layers...)
+}
+
+// newModelShape allocates zero weights for the layer sizes.
+func newModelShape(Internal Activation, Output Activation, layers ...int) *Model {
 	if len(layers) < 2 {
 		panic("there should be at least 2 layers: input and output")
 	}
@@ -28,15 +42,30 @@ func NewModel(source *rand.Rand, Internal Activation, Output Activation, layers
 	for i, c := range layers[:len(layers)-1] {
 		c = c + 1 // include bias
 		r := layers[i+1]
-		randWeights := make([]float64, r*c)
-		for j := range randWeights {
-			randWeights[j] = source.NormFloat64()
-		}
-		model.Weights = append(model.Weights, mat.NewDense(r, c, randWeights))
+		model.Weights = append(model.Weights, mat.NewDense(r, c, nil))
 	}
 	return model
 }
 
+func NewModelLayers(source *rand.Rand, activations []Activation, layers ...int) *Model {
+	if len(activations) != len(layers)-1 {
+		panic(fmt.Sprintf("need one activation per non-input layer: got %d activations for %d layers", len(activations), len(layers)))
+	}
+	model := NewModel(source, Activation{}, Activation{}, layers...)
+	model.Activations = append([]Activation(nil), activations...)
+	return model
+}
+
+func (m Model) activation(layer int) Activation {
+	if len(m.Activations) > 0 {
+		return m.Activations[layer]
+	}
+	if layer == len(m.Weights)-1 {
+		return m.Output
+	}
+	return m.Internal
+}
+
 func (m Model) InputSize() int {
 	_, c := m.Weights[0].Dims()
 	return c - 1
@@ -54,13 +83,14 @@ func (m Model) Predict(start mat.Vector) mat.Vector {
 		input.SetVec(i, start.AtVec(i))
 	}
 	input.SetVec(start.Len(), 1)
-	for _, weights := range m.Weights[:len(m.Weights)-1] {
+	for layer, weights := range m.Weights[:len(m.Weights)-1] {
 		r, _ := weights.Dims()
 		output := mat.NewVecDense(r, nil)
 		output.MulVec(weights, input)
+		activation := m.activation(layer)
 		newIn := make([]float64, 0, output.Len()+1)
 		for i := 0; i < output.Len(); i++ {
-			newIn = append(newIn, m.Internal.Activate(output.AtVec(i)))
+			newIn = append(newIn, activation.Activate(output.AtVec(i)))
 		}
 		newIn = append(newIn, 1)
 		input = mat.NewVecDense(len(newIn), newIn)
@@ -69,8 +99,14 @@ func (m Model) Predict(start mat.Vector) mat.Vector {
 	r, _ := finalw.Dims()
 	output := mat.NewVecDense(r, nil)
 	output.MulVec(finalw, input)
+	if m.VectorOutput != nil {
+		result := mat.NewVecDense(r, nil)
+		m.VectorOutput.Activate(result.RawVector().Data, output.RawVector().Data)
+		return result
+	}
+	activation := m.activation(len(m.Weights) - 1)
 	for i := 0; i < r; i++ {
-		output.SetVec(i, m.Output.Activate(output.AtVec(i)))
+		output.SetVec(i, activation.Activate(output.AtVec(i)))
 	}
 	return output
 }
@@ -96,12 +132,14 @@ func (m Model) String() string {
 	return builder.String()
 }
 
-func (m Model) hasNaN() bool {
+// HasNonFinite reports whether any weight is NaN or infinite, the sign that
+// training has diverged.
+func (m Model) HasNonFinite() bool {
 	for _, weights := range m.Weights {
 		R, C := weights.Dims()
 		for r := 0; r < R; r++ {
 			for c := 0; c < C; c++ {
-				if math.IsNaN(weights.At(r, c)) {
+				if f := weights.At(r, c); math.IsNaN(f) || math.IsInf(f, 0) {
 					return true
 				}
 			}
@@ -123,9 +161,24 @@ func (m *Model) Clone() *Model {
 		weights[i] = nw
 	}
 	return &Model{
-		Weights:  weights,
-		Internal: m.Internal,
-		Output:   m.Output,
+		Weights:      weights,
+		Internal:     m.Internal,
+		Output:       m.Output,
+		Activations:  append([]Activation(nil), m.Activations...),
+		VectorOutput: m.VectorOutput,
+	}
+}
+
+// copyFrom copies the weights of src, a Model of the same shape, into the
+// matrices of m, so every holder of m sees them.
+func (m *Model) copyFrom(src *Model) {
+	for i, w := range m.Weights {
+		R, C := w.Dims()
+		for r := 0; r < R; r++ {
+			for c := 0; c < C; c++ {
+				w.Set(r, c, src.Weights[i].At(r, c))
+			}
+		}
 	}
 }
 
@@ -133,6 +186,49 @@ type TrainingContext struct {
 	*Model
 	GeneratedNodes []*mat.VecDense
 	PreNormalized  []*mat.VecDense
+	// Loss is minimised by training, MSE when nil.
+	Loss Loss
+	// Optimizer applies the gradients, SGD when nil.
+	Optimizer Optimizer
+	// Schedule adjusts the learning rate passed to Train and TrainChunked
+	// for every update; the rate stays fixed when nil.
+	Schedule Schedule
+	// LearningRate is the rate used by the latest update, so the debug
+	// callbacks can report it.
+	LearningRate float64
+	// BatchSize is the number of samples whose gradients Train, and
+	// TrainChunked in Synchronous mode, average before each update. Values
+	// below 2 make Train update after every sample.
+	BatchSize int
+	// Shuffle, when set, randomizes the order of the training set at the
+	// start of every epoch. The caller's slice is left untouched.
+	Shuffle *rand.Rand
+	// EarlyStopping, when set, checks a validation set after every epoch.
+	EarlyStopping *EarlyStopping
+	// Callbacks are notified of the progress of every training method.
+	Callbacks []Callback
+	// Parallel selects how TrainChunked divides the work.
+	Parallel ParallelMode
+	// ClipValue, when positive, limits every gradient component to
+	// ±ClipValue before each update.
+	ClipValue float64
+	// ClipNorm, when positive, scales the gradient of each update down so
+	// its L2 norm, over all layers, is at most ClipNorm. It is applied after
+	// ClipValue.
+	ClipNorm float64
+	// Regularization penalizes large weights, no penalty when zero.
+	Regularization Regularization
+	// Divergence, when set, rolls training back when it stops being finite.
+	Divergence *DivergenceGuard
+	// Batched makes Train push each batch through the layers as one matrix,
+	// a single multiplication per layer, rather than one sample at a time.
+	// It pays off for wide layers and large batches. The sums are taken in a
+	// different order, so the weights differ from unbatched training in the
+	// last bits.
+	Batched bool
+
+	jacobian *mat.Dense
+	batch    batchState
 }
 
 func (tc *TrainingContext) feedForward(input mat.Vector) {
@@ -163,167 +259,527 @@ func (tc *TrainingContext) feedForward(input mat.Vector) {
 	// generate next layers
 	for layer := 1; layer < len(tc.GeneratedNodes)-1; layer++ {
 		tc.PreNormalized[layer].MulVec(tc.Weights[layer-1], tc.GeneratedNodes[layer-1])
+		activation := tc.activation(layer - 1)
 		for i := 0; i < tc.GeneratedNodes[layer].Len()-1; i++ {
 			node := i
-			tc.GeneratedNodes[layer].SetVec(node, tc.Internal.Activate(tc.PreNormalized[layer].AtVec(node)))
+			tc.GeneratedNodes[layer].SetVec(node, activation.Activate(tc.PreNormalized[layer].AtVec(node)))
 		}
 	}
 
 	// generate output layer
 	tc.PreNormalized[len(tc.PreNormalized)-1].MulVec(tc.Weights[len(tc.Weights)-1], tc.GeneratedNodes[len(tc.GeneratedNodes)-2])
+	if tc.VectorOutput != nil {
+		tc.VectorOutput.Activate(tc.GeneratedNodes[len(tc.GeneratedNodes)-1].RawVector().Data, tc.PreNormalized[len(tc.PreNormalized)-1].RawVector().Data)
+		return
+	}
+	output := tc.activation(len(tc.Weights) - 1)
 	for i := 0; i < tc.GeneratedNodes[len(tc.GeneratedNodes)-1].Len(); i++ {
-		tc.GeneratedNodes[len(tc.GeneratedNodes)-1].SetVec(i, tc.Output.Activate(tc.PreNormalized[len(tc.PreNormalized)-1].AtVec(i)))
+		tc.GeneratedNodes[len(tc.GeneratedNodes)-1].SetVec(i, output.Activate(tc.PreNormalized[len(tc.PreNormalized)-1].AtVec(i)))
+	}
+}
+
+func (tc *TrainingContext) loss() Loss {
+	if tc.Loss == nil {
+		return MSE
 	}
+	return tc.Loss
 }
 
-func (tc *TrainingContext) backPropogate(target mat.Vector, lrate float64) float64 {
-	return tc.backPropogateChanges(target, lrate, tc.Weights)
+func (tc *TrainingContext) optimizer() Optimizer {
+	if tc.Optimizer == nil {
+		return &SGD{}
+	}
+	return tc.Optimizer
 }
 
-func (tc *TrainingContext) backPropogateChanges(target mat.Vector, lrate float64, changes []mat.Mutable) float64 {
-	Error := 0.0
-	for i := 0; i < target.Len(); i++ {
-		diff := target.AtVec(i) - tc.GeneratedNodes[len(tc.GeneratedNodes)-1].AtVec(i)
-		Error += (diff * diff) / 2
+func (tc *TrainingContext) rate(epoch, step int, base float64) float64 {
+	base *= tc.Divergence.rateScale()
+	if tc.Schedule != nil {
+		base = tc.Schedule.Rate(epoch, step, base)
 	}
-	Error /= float64(target.Len())
+	tc.LearningRate = base
+	return base
+}
 
-	deltas := make([][]float64, len(tc.GeneratedNodes))
+func (tc *TrainingContext) observe(epoch int, err float64) {
+	if observer, ok := tc.Schedule.(EpochObserver); ok {
+		observer.Observe(epoch, err)
+	}
+}
+
+// epochOrder returns the training samples in the order for the next epoch.
+// order is the working copy to shuffle, reused across epochs.
+func (tc *TrainingContext) epochOrder(trainingSet, order [][]mat.Vector) [][]mat.Vector {
+	if tc.Shuffle == nil {
+		return trainingSet
+	}
+	tc.Shuffle.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	return order
+}
+
+func newGradients(weights []mat.Mutable) []*mat.Dense {
+	grads := make([]*mat.Dense, len(weights))
+	for i, w := range weights {
+		R, C := w.Dims()
+		grads[i] = mat.NewDense(R, C, nil)
+	}
+	return grads
+}
+
+func zeroGradients(grads []*mat.Dense) {
+	for _, g := range grads {
+		g.Zero()
+	}
+}
+
+func scaleGradients(grads []*mat.Dense, factor float64) {
+	for _, g := range grads {
+		g.Scale(factor, g)
+	}
+}
+
+// clip applies ClipValue and then ClipNorm to grads, whose norm is given.
+func (tc *TrainingContext) clip(grads []*mat.Dense, norm float64) {
+	if tc.ClipValue > 0 {
+		for _, g := range grads {
+			g.Apply(func(r, c int, v float64) float64 {
+				return max(min(v, tc.ClipValue), -tc.ClipValue)
+			}, g)
+		}
+		if tc.ClipNorm > 0 {
+			norm = gradientNorm(grads)
+		}
+	}
+	if tc.ClipNorm > 0 && norm > tc.ClipNorm {
+		scaleGradients(grads, tc.ClipNorm/norm)
+	}
+}
+
+// clipSum clips grads, the summed gradients of n samples, as clip would clip
+// their mean, and returns the norm of that mean.
+func (tc *TrainingContext) clipSum(grads []*mat.Dense, n float64) float64 {
+	if tc.ClipValue <= 0 && tc.ClipNorm <= 0 {
+		return gradientNorm(grads) / n
+	}
+	scaleGradients(grads, 1/n)
+	norm := gradientNorm(grads)
+	tc.clip(grads, norm)
+	scaleGradients(grads, n)
+	return norm
+}
+
+func applyGradients(opt Optimizer, weights []mat.Mutable, grads []*mat.Dense, lrate float64) {
+	for layer, g := range grads {
+		opt.Update(layer, weights[layer], g, lrate)
+	}
+}
+
+// backPropogate adds the gradient of the Error for target, with respect to
+// every weight, into grads and returns the Error.
+func (tc *TrainingContext) backPropogate(target mat.Vector, grads []*mat.Dense) float64 {
+	Error, deltas := tc.deltas(target)
+	addGradients(grads, tc.GeneratedNodes, deltas)
+	return Error
+}
+
+// deltas returns the error of the last feedForward and the gradient of that
+// error with respect to the pre-activation nodes of every layer.
+func (tc *TrainingContext) deltas(target mat.Vector) (float64, [][]float64) {
+	final := tc.GeneratedNodes[len(tc.GeneratedNodes)-1]
+	pre := tc.PreNormalized[len(tc.PreNormalized)-1]
 	outputsize := tc.OutputSize()
-	for node := 0; node < outputsize; node++ {
-		deltas[len(deltas)-1] = append(deltas[len(deltas)-1], (tc.GeneratedNodes[len(tc.GeneratedNodes)-1].AtVec(node)-target.AtVec(node))*tc.Output.Derivative(tc.PreNormalized[len(tc.PreNormalized)-1].AtVec(node)))
+
+	// gradient of Error with respect to each output node
+	loss := tc.loss()
+	Error := loss.Value(final, target)
+	outgrad := make([]float64, outputsize)
+	loss.Gradient(outgrad, final, target)
+
+	deltas := make([][]float64, len(tc.GeneratedNodes))
+	deltas[len(deltas)-1] = make([]float64, outputsize)
+	if tc.VectorOutput != nil {
+		if tc.jacobian == nil || tc.jacobian.RawMatrix().Rows != outputsize {
+			tc.jacobian = mat.NewDense(outputsize, outputsize, nil)
+		}
+		tc.VectorOutput.Jacobian(tc.jacobian, pre.RawVector().Data)
+		delta := mat.NewVecDense(outputsize, deltas[len(deltas)-1])
+		delta.MulVec(tc.jacobian.T(), mat.NewVecDense(outputsize, outgrad))
+	} else {
+		output := tc.activation(len(tc.Weights) - 1)
+		for node := 0; node < outputsize; node++ {
+			deltas[len(deltas)-1][node] = outgrad[node] * output.Derivative(pre.AtVec(node))
+		}
 	}
 
 	for layer := len(deltas) - 2; layer > 0; layer-- {
 		deltas[layer] = make([]float64, tc.PreNormalized[layer].Len())
+		activation := tc.activation(layer - 1)
 		for node := 0; node < tc.PreNormalized[layer].Len(); node++ {
 			sum := 0.0
 			for nextnode := 0; nextnode < tc.PreNormalized[layer+1].Len(); nextnode++ {
 				sum += tc.Weights[layer].At(nextnode, node) * deltas[layer+1][nextnode]
 			}
-			deltas[layer][node] = sum * tc.Internal.Derivative(tc.PreNormalized[layer].AtVec(node))
+			deltas[layer][node] = sum * activation.Derivative(tc.PreNormalized[layer].AtVec(node))
 		}
 	}
 
-	for layer, weights := range changes {
-		R, C := weights.Dims()
-		for r := 0; r < R; r++ {
-			for c := 0; c < C; c++ {
-				current := weights.At(r, c)
-				change := lrate * tc.GeneratedNodes[layer].AtVec(c) * deltas[layer+1][r]
-				changes[layer].Set(r, c, current-change)
+	return Error, deltas
+}
+
+func addGradients(grads []*mat.Dense, nodes []*mat.VecDense, deltas [][]float64) {
+	for layer, grad := range grads {
+		raw := grad.RawMatrix()
+		for r := 0; r < raw.Rows; r++ {
+			row := raw.Data[r*raw.Stride : r*raw.Stride+raw.Cols]
+			for c := range row {
+				row[c] += nodes[layer].AtVec(c) * deltas[layer+1][r]
 			}
 		}
 	}
-	return Error
 }
 
+// batchGradients adds the gradient of every sample in batch to grads and
+// returns their summed error.
+func (tc *TrainingContext) batchGradients(batch [][]mat.Vector, grads []*mat.Dense) float64 {
+	batcherror := 0.0
+	for _, set := range batch {
+		tc.feedForward(set[0])
+		batcherror += tc.backPropogate(set[1], grads)
+	}
+	return batcherror
+}
+
+// Train has no error to return: when a DivergenceGuard runs out of retries,
+// training stops with the last good Model and nothing else tells the caller.
+// Use TrainContext or TrainE to get the error wrapping ErrNonFinite.
 func (tc *TrainingContext) Train(trainingSet [][]mat.Vector, iterations int, lrate float64, debug func(epoch int, err float64)) {
-	if debug == nil {
-		debug = func(epoch int, error float64) {}
+	var legacy Callback
+	if debug != nil {
+		legacy = CallbackFuncs{EpochEnd: func(m Metrics) { debug(m.Epoch, m.TrainLoss) }}
 	}
+	tc.train(context.Background(), trainingSet, iterations, max(tc.BatchSize, 1), lrate, tc.gradients(), legacy)
+}
+
+// TrainContext is Train that reports to the Callbacks and stops between
+// updates once ctx is done. The Model keeps the training done so far and
+// ctx.Err() is returned.
+func (tc *TrainingContext) TrainContext(ctx context.Context, trainingSet [][]mat.Vector, iterations int, lrate float64) (*History, error) {
+	return tc.train(ctx, trainingSet, iterations, max(tc.BatchSize, 1), lrate, tc.gradients(), nil)
+}
+
+// epochEnd finishes the metrics of an epoch, notifies the callbacks and
+// reports whether early stopping ends training.
+func (tc *TrainingContext) epochEnd(callbacks callbackList, m Metrics, updates int) bool {
+	if tc.Divergence != nil {
+		tc.Divergence.confirm()
+	}
+	tc.observe(m.Epoch, m.TrainLoss)
+	if updates > 0 {
+		m.GradientNorm /= float64(updates)
+	}
+	m.LearningRate = tc.LearningRate
+	m.ValLoss = math.NaN()
+	stop := false
+	if tc.EarlyStopping != nil {
+		m.ValLoss, stop = tc.EarlyStopping.epochEnd(m.Epoch, tc)
+	}
+	m.Model = tc.Model
+	callbacks.OnEpochEnd(m)
+	return stop
+}
+
+// train updates the Model once per batch of samples with the mean of the
+// gradients summed by gradients.
+func (tc *TrainingContext) train(ctx context.Context, trainingSet [][]mat.Vector, iterations int, batch int, lrate float64, gradients func(batch [][]mat.Vector, grads []*mat.Dense) float64, extra Callback) (*History, error) {
+	history := new(History)
+	callbacks := tc.callbacks(history, extra)
+	started := time.Now()
+	opt := tc.optimizer()
+	opt.Init(tc.Weights)
+	grads := newGradients(tc.Weights)
+	if tc.EarlyStopping != nil {
+		tc.EarlyStopping.reset()
+		defer tc.EarlyStopping.restore(tc)
+	}
+	if tc.Divergence != nil {
+		tc.Divergence.reset()
+	}
+	order := slices.Clone(trainingSet)
+	step := 0
+epochs:
 	for i := 0; i < iterations; i++ {
-		totalerror := 0.0
-		for _, set := range trainingSet {
-			tc.feedForward(set[0])
-			totalerror += tc.backPropogate(set[1], lrate)
+		if tc.Divergence != nil {
+			tc.Divergence.save(tc, i, step)
+		}
+		callbacks.OnEpochStart(Metrics{Epoch: i, Step: step, LearningRate: tc.LearningRate, Elapsed: time.Since(started), Model: tc.Model})
+		samples := tc.epochOrder(trainingSet, order)
+		epoch := Metrics{Epoch: i}
+		updates := 0
+		for start := 0; start < len(samples); start += batch {
+			if err := ctx.Err(); err != nil {
+				return history, err
+			}
+			end := min(start+batch, len(samples))
+			zeroGradients(grads)
+			batcherror := gradients(samples[start:end], grads)
+			batcherror += tc.Regularization.regularize(grads, tc.Weights, float64(end-start))
+			scaleGradients(grads, 1/float64(end-start))
+			norm := gradientNorm(grads)
+			tc.clip(grads, norm)
+			rate := tc.rate(i, step, lrate)
+			applyGradients(opt, tc.Weights, grads, rate)
+			step++
+			if tc.diverged(batcherror) {
+				var err error
+				i, step, err = tc.rollback(callbacks, Metrics{
+					Epoch:        i,
+					Step:         step,
+					TrainLoss:    batcherror,
+					LearningRate: rate,
+					GradientNorm: norm,
+					Elapsed:      time.Since(started),
+				})
+				if err != nil {
+					return history, err
+				}
+				opt = tc.optimizer()
+				opt.Init(tc.Weights)
+				i--
+				continue epochs
+			}
+			updates++
+			epoch.TrainLoss += batcherror
+			epoch.GradientNorm += norm
+			callbacks.OnBatchEnd(Metrics{
+				Epoch:        i,
+				Step:         step,
+				TrainLoss:    batcherror,
+				LearningRate: rate,
+				GradientNorm: norm,
+				Elapsed:      time.Since(started),
+				Model:        tc.Model,
+			})
+		}
+		epoch.Step, epoch.Elapsed = step, time.Since(started)
+		if tc.epochEnd(callbacks, epoch, updates) {
+			break
 		}
-		debug(i, totalerror)
 	}
+	return history, nil
 }
 
 type updateStep struct {
 	*Model
-	data [][]mat.Vector
+	data  [][]mat.Vector
+	lrate float64
 }
 
+type update struct {
+	grads []*mat.Dense
+	lrate float64
+	err   float64
+	norm  float64
+}
+
+type updatedModel struct {
+	*Model
+	applied []update
+}
+
+// TrainChunked has no error to return: when a DivergenceGuard runs out of
+// retries, training stops with the last good Model and nothing else tells the
+// caller, and when Hogwild rejects the Optimizer nothing is trained. Use
+// TrainChunkedContext to get the error.
 func (tc *TrainingContext) TrainChunked(trainingSet [][]mat.Vector, iterations int, workers int, chunksize int, lrate float64, debug func(epoch int, current *Model)) {
-	if debug == nil {
-		debug = func(epoch int, current *Model) {}
+	var legacy Callback
+	if debug != nil {
+		legacy = CallbackFuncs{EpochEnd: func(m Metrics) { debug(m.Epoch, m.Model) }}
 	}
-	stepch := make(chan updateStep)
-	changech := make(chan []mat.Mutable)
-	workerGroup := sync.WaitGroup{}
-	workerGroup.Add(workers)
+	tc.trainChunked(context.Background(), trainingSet, iterations, workers, chunksize, lrate, legacy)
+}
+
+// TrainChunkedContext is TrainChunked that reports to the Callbacks and stops
+// once ctx is done. Steps already handed to the workers are applied, then
+// every goroutine exits before it returns the partially trained Model and
+// ctx.Err().
+func (tc *TrainingContext) TrainChunkedContext(ctx context.Context, trainingSet [][]mat.Vector, iterations int, workers int, chunksize int, lrate float64) (*History, error) {
+	return tc.trainChunked(ctx, trainingSet, iterations, workers, chunksize, lrate, nil)
+}
+
+func (tc *TrainingContext) trainChunked(ctx context.Context, trainingSet [][]mat.Vector, iterations int, workers int, chunksize int, lrate float64, extra Callback) (*History, error) {
+	switch tc.Parallel {
+	case Synchronous:
+		return tc.trainSynchronous(ctx, trainingSet, iterations, workers, chunksize, lrate, extra)
+	case Hogwild:
+		return tc.trainHogwild(ctx, trainingSet, iterations, workers, chunksize, lrate, extra)
+	}
+	history := new(History)
+	callbacks := tc.callbacks(history, extra)
+	started := time.Now()
+	pool := tc.startChunked(workers)
+	defer func() { pool.stop() }()
+
+	if tc.EarlyStopping != nil {
+		tc.EarlyStopping.reset()
+		defer tc.EarlyStopping.restore(tc)
+	}
+	if tc.Divergence != nil {
+		tc.Divergence.reset()
+	}
+	order := slices.Clone(trainingSet)
+	step, applied := 0, 0
+	var err error
+training:
+	for i := 0; i < iterations; i++ {
+		if tc.Divergence != nil {
+			tc.Divergence.save(tc, i, applied)
+		}
+		callbacks.OnEpochStart(Metrics{Epoch: i, Step: applied, LearningRate: tc.LearningRate, Elapsed: time.Since(started), Model: tc.Model})
+		samples := tc.epochOrder(trainingSet, order)
+		epoch := Metrics{Epoch: i}
+		updates := 0
+		for group := 0; group < len(samples); group += workers * chunksize {
+			if err = ctx.Err(); err != nil {
+				break training
+			}
+			groupEnd := min(group+workers*chunksize, len(samples))
+			pool.groupch <- (groupEnd - group + chunksize - 1) / chunksize
+			for start := group; start < groupEnd; start += chunksize {
+				pool.stepch <- updateStep{
+					Model: tc.Model,
+					data:  samples[start:min(start+chunksize, groupEnd)],
+					lrate: tc.rate(i, step, lrate),
+				}
+				step++
+			}
+			updated := <-pool.NewModelCh
+			tc.Model = updated.Model
+			for _, change := range updated.applied {
+				applied++
+				if tc.diverged(change.err) {
+					// the pool holds its own Model and Optimizer, so it is
+					// restarted from the rolled back ones
+					pool.stop()
+					i, applied, err = tc.rollback(callbacks, Metrics{
+						Epoch:        i,
+						Step:         applied,
+						TrainLoss:    change.err,
+						LearningRate: change.lrate,
+						GradientNorm: change.norm,
+						Elapsed:      time.Since(started),
+					})
+					step = applied
+					pool = tc.startChunked(workers)
+					if err != nil {
+						break training
+					}
+					i--
+					continue training
+				}
+				updates++
+				epoch.TrainLoss += change.err
+				epoch.GradientNorm += change.norm
+				callbacks.OnBatchEnd(Metrics{
+					Epoch:        i,
+					Step:         applied,
+					TrainLoss:    change.err,
+					LearningRate: change.lrate,
+					GradientNorm: change.norm,
+					Elapsed:      time.Since(started),
+					Model:        tc.Model,
+				})
+			}
+		}
+		epoch.Step, epoch.Elapsed = applied, time.Since(started)
+		if tc.epochEnd(callbacks, epoch, updates) {
+			break
+		}
+	}
+	return history, err
+}
+
+// chunkedPool is the workers and updater of an Asynchronous TrainChunked run.
+type chunkedPool struct {
+	stepch      chan updateStep
+	groupch     chan int
+	NewModelCh  chan updatedModel
+	workerGroup sync.WaitGroup
+}
+
+// startChunked starts the goroutines, which update a copy of the Model with
+// the Optimizer of tc.
+func (tc *TrainingContext) startChunked(workers int) *chunkedPool {
+	opt := tc.optimizer()
+	opt.Init(tc.Weights)
+	pool := &chunkedPool{
+		stepch:     make(chan updateStep),
+		groupch:    make(chan int),
+		NewModelCh: make(chan updatedModel),
+	}
+	changech := make(chan update)
+	pool.workerGroup.Add(workers)
 	for i := 0; i < workers; i++ {
 		go func() {
-			defer workerGroup.Done()
-			local := new(TrainingContext)
-			for step := range stepch {
-				changes := make([]mat.Mutable, len(tc.Model.Weights))
-				for i, w := range tc.Model.Weights {
-					R, C := w.Dims()
-					changes[i] = mat.NewDense(R, C, nil)
-				}
+			defer pool.workerGroup.Done()
+			local := &TrainingContext{Loss: tc.Loss}
+			for step := range pool.stepch {
+				grads := newGradients(step.Weights)
 				local.Model = step.Model
+				err := 0.0
 				for _, data := range step.data {
 					local.feedForward(data[0])
-					local.backPropogateChanges(data[1], lrate, changes)
+					err += local.backPropogate(data[1], grads)
 				}
-				changech <- changes
+				err += tc.Regularization.regularize(grads, step.Weights, float64(len(step.data)))
+				norm := tc.clipSum(grads, float64(len(step.data)))
+				changech <- update{grads: grads, lrate: step.lrate, err: err, norm: norm}
 			}
 		}()
 	}
 
-	NewModelCh := make(chan *Model)
+	// the updater applies each group of steps and answers with the new Model
 	go func() {
 		model := tc.Model.Clone()
-		layerUpdatersCh := make([]chan mat.Matrix, len(model.Weights))
+		layerUpdatersCh := make([]chan update, len(model.Weights))
 		updateFlag := sync.WaitGroup{}
 		for i, weights := range model.Weights {
-			updateCh := make(chan mat.Matrix)
+			updateCh := make(chan update)
 			layerUpdatersCh[i] = updateCh
 			go func() {
-				R, C := weights.Dims()
 				for update := range updateCh {
-					for r := 0; r < R; r++ {
-						for c := 0; c < C; c++ {
-							weights.Set(r, c, weights.At(r, c) + update.At(r, c))
-						}
-					}
+					opt.Update(i, weights, update.grads[i], update.lrate)
 					updateFlag.Done()
 				}
 			}()
 		}
-		changeCounter := 0
-		for change := range changech {
-			updateFlag.Add(len(change))
-			for i, cha := range change {
-				layerUpdatersCh[i] <- cha
-			}
-			updateFlag.Wait()
-			changeCounter++
-			if changeCounter >= workers {
-				NewModelCh <- model.Clone()
-				changeCounter = 0
+		for size := range pool.groupch {
+			applied := make([]update, 0, size)
+			for i := 0; i < size; i++ {
+				change := <-changech
+				updateFlag.Add(len(change.grads))
+				for _, updateCh := range layerUpdatersCh {
+					updateCh <- change
+				}
+				updateFlag.Wait()
+				applied = append(applied, change)
 			}
+			pool.NewModelCh <- updatedModel{Model: model.Clone(), applied: applied}
+		}
+		for _, updateCh := range layerUpdatersCh {
+			close(updateCh)
 		}
-		NewModelCh <- model
-		close(NewModelCh)
+		close(pool.NewModelCh)
 	}()
+	return pool
+}
 
-	stepCounter := 0
-	for epoch := 0; epoch < iterations; epoch++ {
-		for start := 0; start < len(trainingSet); start += chunksize {
-			end := start + chunksize
-			if end > len(trainingSet) {
-				end = len(trainingSet)
-			}
-			stepch <- updateStep{
-				Model: tc.Model,
-				data:  trainingSet[start:end],
-			}
-			stepCounter++
-			if stepCounter >= workers {
-				tc.Model = <-NewModelCh
-				stepCounter = 0
-			}
-		}
-		debug(epoch, tc.Model)
-	}
-	close(stepch)
-	workerGroup.Wait()
-	close(changech)
-	for tc.Model = range NewModelCh {
+// stop waits for every goroutine of the pool to exit.
+func (pool *chunkedPool) stop() {
+	close(pool.groupch)
+	close(pool.stepch)
+	pool.workerGroup.Wait()
+	for range pool.NewModelCh {
 	}
 }
@@ -1,10 +1,14 @@
 package goregression
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"runtime"
 	"testing"
+	"time"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -195,7 +199,7 @@ func TestTraining(t *testing.T) {
 		if epoch%1000 == 999 {
 			t.Logf("Reg iteration %d: error %f", epoch, err)
 		}
-		if checkNaN && train.Model.hasNaN() {
+		if checkNaN && train.Model.HasNonFinite() {
 			t.Logf("Regession Model, epoch %d\n%s", epoch, train.Model)
 			checkNaN = false
 		}
@@ -347,3 +351,217 @@ func TestTrainingVSChunk(t *testing.T) {
 		}
 	}
 }
+
+func TestPerLayerActivations(t *testing.T) {
+	model := Model{
+		Weights: []mat.Mutable{
+			mat.NewDense(2, 2, []float64{
+				1, 0,
+				-1, 0,
+			}),
+			mat.NewDense(1, 3, []float64{
+				1, 1, 0,
+			}),
+			mat.NewDense(1, 2, []float64{
+				2, 1,
+			}),
+		},
+		Activations: []Activation{ReLU, Linear(3), Linear(1)},
+	}
+	for _, test := range []struct{ input, expect float64 }{
+		{input: 2, expect: 13},
+		{input: -4, expect: 25},
+	} {
+		out := model.Predict(mat.NewVecDense(1, []float64{test.input}))
+		if out.AtVec(0) != test.expect {
+			t.Errorf("Predict(%v) = %v, want %v", test.input, out.AtVec(0), test.expect)
+		}
+	}
+
+	xorTest := [][]mat.Vector{
+		{mat.NewVecDense(2, []float64{0, 0}), mat.NewVecDense(1, []float64{0})},
+		{mat.NewVecDense(2, []float64{1, 0}), mat.NewVecDense(1, []float64{1})},
+		{mat.NewVecDense(2, []float64{0, 1}), mat.NewVecDense(1, []float64{1})},
+		{mat.NewVecDense(2, []float64{1, 1}), mat.NewVecDense(1, []float64{0})},
+	}
+	train := TrainingContext{
+		Model: NewModelLayers(rand.New(rand.NewPCG(30, 34)), []Activation{ReLU, Tanh, Sigmoid}, 2, 4, 4, 1),
+	}
+	train.Train(xorTest, 3000, 0.4, nil)
+	for _, test := range xorTest {
+		input, expect := test[0], test[1]
+		output := train.Predict(input)
+		if math.Round(output.AtVec(0)) != expect.AtVec(0) {
+			t.Errorf("XOR test failed. Got (%f XOR %f) == %f, want %f", input.AtVec(0), input.AtVec(1), output.AtVec(0), expect.AtVec(0))
+		}
+	}
+	clone := train.Clone()
+	clone.Activations[0] = Linear(1)
+	if train.Activations[0].Show() != "ReLU" {
+		t.Error("Clone shares the Activations slice")
+	}
+}
+
+func TestMiniBatch(t *testing.T) {
+	regTest := [][]mat.Vector{
+		{mat.NewVecDense(1, []float64{3}), mat.NewVecDense(1, []float64{6})},
+		{mat.NewVecDense(1, []float64{4}), mat.NewVecDense(1, []float64{8})},
+		{mat.NewVecDense(1, []float64{5}), mat.NewVecDense(1, []float64{10})},
+		{mat.NewVecDense(1, []float64{6}), mat.NewVecDense(1, []float64{12})},
+		{mat.NewVecDense(1, []float64{7}), mat.NewVecDense(1, []float64{14})},
+	}
+	start := NewModel(rand.New(rand.NewPCG(3453, 9988)), Sigmoid, Linear(1), 1, 3, 1)
+
+	// one epoch with batches of 2, 2 and 1 samples, computed by hand
+	expect := TrainingContext{Model: start.Clone()}
+	grads := newGradients(expect.Weights)
+	for _, batch := range [][][]mat.Vector{regTest[0:2], regTest[2:4], regTest[4:5]} {
+		zeroGradients(grads)
+		for _, set := range batch {
+			expect.feedForward(set[0])
+			expect.backPropogate(set[1], grads)
+		}
+		for layer, g := range grads {
+			R, C := g.Dims()
+			for r := 0; r < R; r++ {
+				for c := 0; c < C; c++ {
+					w := expect.Weights[layer]
+					w.Set(r, c, w.At(r, c)-0.01*(g.At(r, c)/float64(len(batch))))
+				}
+			}
+		}
+	}
+
+	train := TrainingContext{Model: start.Clone(), BatchSize: 2}
+	train.Train(regTest, 1, 0.01, nil)
+	for layer, weights := range expect.Weights {
+		R, C := weights.Dims()
+		for r := 0; r < R; r++ {
+			for c := 0; c < C; c++ {
+				if math.Abs(weights.At(r, c)-train.Weights[layer].At(r, c)) > 1e-15 {
+					t.Errorf("mismatch [%d][%d][%d]: %v != %v", layer, r, c, train.Weights[layer].At(r, c), weights.At(r, c))
+				}
+			}
+		}
+	}
+
+	// batches of 1 are the original online updates
+	online := TrainingContext{Model: start.Clone()}
+	single := TrainingContext{Model: start.Clone(), BatchSize: 1}
+	online.Train(regTest, 10, 0.01, nil)
+	single.Train(regTest, 10, 0.01, nil)
+	sameWeights(t, online.Model, single.Model)
+}
+
+func TestShuffle(t *testing.T) {
+	regTest := [][]mat.Vector{
+		{mat.NewVecDense(1, []float64{3}), mat.NewVecDense(1, []float64{6})},
+		{mat.NewVecDense(1, []float64{4}), mat.NewVecDense(1, []float64{8})},
+		{mat.NewVecDense(1, []float64{5}), mat.NewVecDense(1, []float64{10})},
+		{mat.NewVecDense(1, []float64{6}), mat.NewVecDense(1, []float64{12})},
+	}
+	start := NewModel(rand.New(rand.NewPCG(3453, 9988)), Sigmoid, Linear(1), 1, 3, 3, 1)
+	first := TrainingContext{Model: start.Clone(), Shuffle: rand.New(rand.NewPCG(1, 1))}
+	second := TrainingContext{Model: start.Clone(), Shuffle: rand.New(rand.NewPCG(1, 1))}
+	chunked := TrainingContext{Model: start.Clone(), Shuffle: rand.New(rand.NewPCG(1, 1))}
+	fixed := TrainingContext{Model: start.Clone()}
+	first.Train(regTest, 10, 0.05, nil)
+	second.Train(regTest, 10, 0.05, nil)
+	chunked.TrainChunked(regTest, 10, 1, 1, 0.05, nil)
+	fixed.Train(regTest, 10, 0.05, nil)
+
+	sameWeights(t, first.Model, second.Model)
+	sameWeights(t, first.Model, chunked.Model)
+	if first.Weights[0].At(0, 0) == fixed.Weights[0].At(0, 0) {
+		t.Error("shuffled training matches unshuffled training")
+	}
+	if regTest[0][0].AtVec(0) != 3 || regTest[3][0].AtVec(0) != 6 {
+		t.Error("training set was reordered in place")
+	}
+}
+
+func TestTrainCancel(t *testing.T) {
+	start := NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1)
+	expect := TrainingContext{Model: start.Clone()}
+	expect.Train(xorSet, 4, 0.3, nil)
+
+	before := runtime.NumGoroutine()
+	for _, chunked := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		train := TrainingContext{
+			Model: start.Clone(),
+			Callbacks: []Callback{CallbackFuncs{EpochEnd: func(m Metrics) {
+				if m.Epoch == 3 {
+					cancel()
+				}
+			}}},
+		}
+		var history *History
+		var err error
+		if chunked {
+			history, err = train.TrainChunkedContext(ctx, xorSet, 100, 1, 1, 0.3)
+		} else {
+			history, err = train.TrainContext(ctx, xorSet, 100, 0.3)
+		}
+		if len(history.Epochs) != 4 {
+			t.Errorf("chunked %v: history has %d epochs, want 4", chunked, len(history.Epochs))
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("chunked %v: got error %v, want %v", chunked, err, context.Canceled)
+		}
+		sameWeights(t, expect.Model, train.Model)
+	}
+	// the chunked workers and updaters should all have exited
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines left running", after-before)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	start := NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1)
+	for _, chunked := range []bool{false, true} {
+		var batches []Metrics
+		epochStarts := 0
+		train := TrainingContext{
+			Model:     start.Clone(),
+			BatchSize: 2,
+			Callbacks: []Callback{CallbackFuncs{
+				EpochStart: func(Metrics) { epochStarts++ },
+				BatchEnd:   func(m Metrics) { batches = append(batches, m) },
+			}},
+		}
+		var history *History
+		var err error
+		if chunked {
+			history, err = train.TrainChunkedContext(context.Background(), xorSet, 3, 2, 1, 0.3)
+		} else {
+			history, err = train.TrainContext(context.Background(), xorSet, 3, 0.3)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// chunked applies one update per chunk of one sample, Train one per batch of two
+		perEpoch := 2
+		if chunked {
+			perEpoch = 4
+		}
+		if epochStarts != 3 || len(history.Epochs) != 3 || len(batches) != 3*perEpoch {
+			t.Fatalf("chunked %v: %d epoch starts, %d epochs, %d batches", chunked, epochStarts, len(history.Epochs), len(batches))
+		}
+		for i, m := range history.Epochs {
+			sum := 0.0
+			for _, b := range batches[i*perEpoch : (i+1)*perEpoch] {
+				sum += b.TrainLoss
+			}
+			if m.Epoch != i || m.Step != (i+1)*perEpoch || m.TrainLoss != sum || !(m.TrainLoss > 0) {
+				t.Errorf("chunked %v: epoch %d metrics %+v, batch loss sum %v", chunked, i, m, sum)
+			}
+			if !math.IsNaN(m.ValLoss) || m.LearningRate != 0.3 || !(m.GradientNorm > 0) || m.Model == nil {
+				t.Errorf("chunked %v: epoch %d metrics %+v", chunked, i, m)
+			}
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package goregression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Optimizer turns gradients into weight updates.
+type Optimizer interface {
+	// Init prepares the optimizer for weights of the given shapes. State that
+	// already matches the shapes is kept, so training can be resumed.
+	Init(weights []mat.Mutable)
+	// Update applies the gradient of one layer to its weights. Different
+	// layers may be updated concurrently.
+	Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64)
+}
+
+// optimizerState holds the per-layer matrices an optimizer accumulates, such
+// as velocities or squared gradient averages, so it can be checkpointed.
+type optimizerState struct {
+	slots [][]*mat.Dense // [slot][layer]
+	steps []int          // updates applied to each layer
+}
+
+func (s *optimizerState) state() *optimizerState {
+	return s
+}
+
+func (s *optimizerState) init(slots int, weights []mat.Mutable) {
+	if len(s.slots) == slots && len(s.steps) == len(weights) {
+		matches := true
+		for _, slot := range s.slots {
+			for layer, w := range weights {
+				R, C := w.Dims()
+				if r, c := slot[layer].Dims(); r != R || c != C {
+					matches = false
+				}
+			}
+		}
+		if matches {
+			return
+		}
+	}
+	s.slots = make([][]*mat.Dense, slots)
+	for i := range s.slots {
+		s.slots[i] = make([]*mat.Dense, len(weights))
+		for layer, w := range weights {
+			R, C := w.Dims()
+			s.slots[i][layer] = mat.NewDense(R, C, nil)
+		}
+	}
+	s.steps = make([]int, len(weights))
+}
+
+func (s *optimizerState) clone() optimizerState {
+	c := optimizerState{
+		slots: make([][]*mat.Dense, len(s.slots)),
+		steps: append([]int(nil), s.steps...),
+	}
+	for i, slot := range s.slots {
+		c.slots[i] = make([]*mat.Dense, len(slot))
+		for layer, m := range slot {
+			c.slots[i][layer] = mat.DenseCopyOf(m)
+		}
+	}
+	return c
+}
+
+// stateful is implemented by the optimizers in this package.
+type stateful interface {
+	Optimizer
+	state() *optimizerState
+	clone() Optimizer
+}
+
+func eachWeight(weights mat.Mutable, gradient mat.Matrix, update func(r, c int, w, g float64) float64) {
+	R, C := weights.Dims()
+	for r := 0; r < R; r++ {
+		for c := 0; c < C; c++ {
+			weights.Set(r, c, update(r, c, weights.At(r, c), gradient.At(r, c)))
+		}
+	}
+}
+
+// SGD is plain gradient descent, the default Optimizer.
+type SGD struct {
+	optimizerState
+}
+
+func (o *SGD) Init(weights []mat.Mutable) {
+	o.init(0, weights)
+}
+
+func (o *SGD) Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64) {
+	eachWeight(weights, gradient, func(r, c int, w, g float64) float64 {
+		return w - lrate*g
+	})
+}
+
+func (o *SGD) clone() Optimizer {
+	c := *o
+	c.optimizerState = o.optimizerState.clone()
+	return &c
+}
+
+type Momentum struct {
+	Momentum float64
+	optimizerState
+}
+
+func NewMomentum(momentum float64) *Momentum {
+	return &Momentum{Momentum: momentum}
+}
+
+func (o *Momentum) Init(weights []mat.Mutable) {
+	o.init(1, weights)
+}
+
+func (o *Momentum) Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64) {
+	velocity := o.slots[0][layer]
+	eachWeight(weights, gradient, func(r, c int, w, g float64) float64 {
+		v := o.Momentum*velocity.At(r, c) - lrate*g
+		velocity.Set(r, c, v)
+		return w + v
+	})
+}
+
+func (o *Momentum) clone() Optimizer {
+	c := *o
+	c.optimizerState = o.optimizerState.clone()
+	return &c
+}
+
+// Nesterov is momentum evaluated at the look-ahead position, using the
+// reformulation that only needs the gradient at the current weights.
+type Nesterov struct {
+	Momentum float64
+	optimizerState
+}
+
+func NewNesterov(momentum float64) *Nesterov {
+	return &Nesterov{Momentum: momentum}
+}
+
+func (o *Nesterov) Init(weights []mat.Mutable) {
+	o.init(1, weights)
+}
+
+func (o *Nesterov) Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64) {
+	velocity := o.slots[0][layer]
+	eachWeight(weights, gradient, func(r, c int, w, g float64) float64 {
+		prev := velocity.At(r, c)
+		v := o.Momentum*prev - lrate*g
+		velocity.Set(r, c, v)
+		return w - o.Momentum*prev + (1+o.Momentum)*v
+	})
+}
+
+func (o *Nesterov) clone() Optimizer {
+	c := *o
+	c.optimizerState = o.optimizerState.clone()
+	return &c
+}
+
+type AdaGrad struct {
+	Epsilon float64
+	optimizerState
+}
+
+func NewAdaGrad() *AdaGrad {
+	return &AdaGrad{Epsilon: 1e-8}
+}
+
+func (o *AdaGrad) Init(weights []mat.Mutable) {
+	o.init(1, weights)
+}
+
+func (o *AdaGrad) Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64) {
+	sum := o.slots[0][layer]
+	eachWeight(weights, gradient, func(r, c int, w, g float64) float64 {
+		s := sum.At(r, c) + g*g
+		sum.Set(r, c, s)
+		return w - lrate*g/(math.Sqrt(s)+o.Epsilon)
+	})
+}
+
+func (o *AdaGrad) clone() Optimizer {
+	c := *o
+	c.optimizerState = o.optimizerState.clone()
+	return &c
+}
+
+type RMSProp struct {
+	Decay   float64
+	Epsilon float64
+	optimizerState
+}
+
+func NewRMSProp() *RMSProp {
+	return &RMSProp{Decay: 0.9, Epsilon: 1e-8}
+}
+
+func (o *RMSProp) Init(weights []mat.Mutable) {
+	o.init(1, weights)
+}
+
+func (o *RMSProp) Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64) {
+	mean := o.slots[0][layer]
+	eachWeight(weights, gradient, func(r, c int, w, g float64) float64 {
+		m := o.Decay*mean.At(r, c) + (1-o.Decay)*g*g
+		mean.Set(r, c, m)
+		return w - lrate*g/(math.Sqrt(m)+o.Epsilon)
+	})
+}
+
+func (o *RMSProp) clone() Optimizer {
+	c := *o
+	c.optimizerState = o.optimizerState.clone()
+	return &c
+}
+
+type Adam struct {
+	Beta1   float64
+	Beta2   float64
+	Epsilon float64
+	optimizerState
+}
+
+func NewAdam() *Adam {
+	return &Adam{Beta1: 0.9, Beta2: 0.999, Epsilon: 1e-8}
+}
+
+func (o *Adam) Init(weights []mat.Mutable) {
+	o.init(2, weights)
+}
+
+func (o *Adam) Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64) {
+	o.adam(layer, weights, gradient, lrate, 0)
+}
+
+func (o *Adam) adam(layer int, weights mat.Mutable, gradient mat.Matrix, lrate, decay float64) {
+	first, second := o.slots[0][layer], o.slots[1][layer]
+	o.steps[layer]++
+	correct1 := 1 - math.Pow(o.Beta1, float64(o.steps[layer]))
+	correct2 := 1 - math.Pow(o.Beta2, float64(o.steps[layer]))
+	eachWeight(weights, gradient, func(r, c int, w, g float64) float64 {
+		m := o.Beta1*first.At(r, c) + (1-o.Beta1)*g
+		v := o.Beta2*second.At(r, c) + (1-o.Beta2)*g*g
+		first.Set(r, c, m)
+		second.Set(r, c, v)
+		return w - lrate*(m/correct1/(math.Sqrt(v/correct2)+o.Epsilon)+decay*w)
+	})
+}
+
+func (o *Adam) clone() Optimizer {
+	c := *o
+	c.optimizerState = o.optimizerState.clone()
+	return &c
+}
+
+// AdamW is Adam with weight decay applied directly to the weights instead of
+// through the gradient.
+type AdamW struct {
+	Adam
+	WeightDecay float64
+}
+
+func NewAdamW(weightDecay float64) *AdamW {
+	return &AdamW{Adam: *NewAdam(), WeightDecay: weightDecay}
+}
+
+func (o *AdamW) Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64) {
+	o.adam(layer, weights, gradient, lrate, o.WeightDecay)
+}
+
+func (o *AdamW) clone() Optimizer {
+	c := *o
+	c.optimizerState = o.optimizerState.clone()
+	return &c
+}
@@ -0,0 +1,79 @@
+package goregression
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var xorSet = [][]mat.Vector{
+	{mat.NewVecDense(2, []float64{0, 0}), mat.NewVecDense(1, []float64{0})},
+	{mat.NewVecDense(2, []float64{1, 0}), mat.NewVecDense(1, []float64{1})},
+	{mat.NewVecDense(2, []float64{0, 1}), mat.NewVecDense(1, []float64{1})},
+	{mat.NewVecDense(2, []float64{1, 1}), mat.NewVecDense(1, []float64{0})},
+}
+
+func TestOptimizers(t *testing.T) {
+	for _, test := range []struct {
+		opt   Optimizer
+		lrate float64
+	}{
+		{&SGD{}, 0.4},
+		{NewMomentum(0.9), 0.1},
+		{NewNesterov(0.9), 0.1},
+		{NewAdaGrad(), 0.3},
+		{NewRMSProp(), 0.01},
+		{NewAdam(), 0.02},
+		{NewAdamW(0.001), 0.02},
+	} {
+		train := TrainingContext{
+			Model:     NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1),
+			Optimizer: test.opt,
+		}
+		var final float64
+		train.Train(xorSet, 2000, test.lrate, func(epoch int, err float64) { final = err })
+		if final > 0.01 {
+			t.Errorf("%T: final error %f", test.opt, final)
+		}
+	}
+}
+
+func TestMomentumZeroIsSGD(t *testing.T) {
+	sgd := TrainingContext{Model: NewModel(rand.New(rand.NewPCG(1, 2)), Tanh, Linear(1), 2, 3, 1)}
+	momentum := TrainingContext{Model: sgd.Clone(), Optimizer: NewMomentum(0)}
+	sgd.Train(xorSet, 20, 0.1, nil)
+	momentum.Train(xorSet, 20, 0.1, nil)
+	sameWeights(t, sgd.Model, momentum.Model)
+}
+
+func TestCheckpointResume(t *testing.T) {
+	straight := TrainingContext{
+		Model:     NewModel(rand.New(rand.NewPCG(5, 6)), Tanh, Sigmoid, 2, 4, 1),
+		Optimizer: NewAdam(),
+	}
+	resumed := TrainingContext{
+		Model:     straight.Clone(),
+		Optimizer: NewAdam(),
+	}
+	straight.Train(xorSet, 40, 0.01, nil)
+
+	resumed.Train(xorSet, 25, 0.01, nil)
+	text, err := json.Marshal(resumed.Checkpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(text, &checkpoint); err != nil {
+		t.Fatal(err)
+	}
+	resumed = TrainingContext{}
+	resumed.Restore(checkpoint)
+	if _, ok := resumed.Optimizer.(*Adam); !ok {
+		t.Fatalf("restored optimizer is %T", resumed.Optimizer)
+	}
+	resumed.Train(xorSet, 15, 0.01, nil)
+
+	sameWeights(t, straight.Model, resumed.Model)
+}
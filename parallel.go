@@ -0,0 +1,100 @@
+package goregression
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ParallelMode selects how TrainChunked shares the work between its workers.
+type ParallelMode int
+
+const (
+	// Asynchronous hands chunks of up to workers*chunksize samples to the
+	// workers and applies each chunk's gradient as it arrives. The order of
+	// the updates depends on goroutine timing.
+	Asynchronous ParallelMode = iota
+	// Synchronous splits every batch of BatchSize samples into chunks, sums
+	// the workers' gradients in sample order and applies their mean once, as
+	// Train does. Results are identical for any worker count and chunk size;
+	// when BatchSize is unset a batch is workers*chunksize samples.
+	Synchronous
+	// Hogwild lets every worker read and update one shared copy of the
+	// weights without locks, each stepping its own copy of the Optimizer, so
+	// Optimizers from outside this package are rejected. Updates may be
+	// computed from weights other workers are halfway through changing, so
+	// results vary from run to run. OnBatchEnd is given no Model; the Model
+	// is brought up to date at the end of every epoch, and the Optimizer
+	// with the state of the first worker's copy only. Every step still
+	// copies all shared weights into the worker and adds its change back
+	// one weight at a time, so Hogwild is not faster than Asynchronous.
+	Hogwild
+)
+
+// sampleGradient is what a worker keeps of a sample so its gradient can be
+// added to the batch in order.
+type sampleGradient struct {
+	err    float64
+	nodes  []*mat.VecDense
+	deltas [][]float64
+}
+
+type gradientJob struct {
+	*Model
+	data    [][]mat.Vector
+	results []sampleGradient
+}
+
+func (tc *TrainingContext) trainSynchronous(ctx context.Context, trainingSet [][]mat.Vector, iterations int, workers int, chunksize int, lrate float64, extra Callback) (*History, error) {
+	jobs := make(chan gradientJob)
+	done := sync.WaitGroup{}
+	workerGroup := sync.WaitGroup{}
+	workerGroup.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer workerGroup.Done()
+			local := &TrainingContext{Loss: tc.Loss}
+			for job := range jobs {
+				local.Model = job.Model
+				for s, data := range job.data {
+					local.feedForward(data[0])
+					err, deltas := local.deltas(data[1])
+					nodes := make([]*mat.VecDense, len(local.GeneratedNodes)-1)
+					for layer := range nodes {
+						nodes[layer] = mat.VecDenseCopyOf(local.GeneratedNodes[layer])
+					}
+					job.results[s] = sampleGradient{err: err, nodes: nodes, deltas: deltas}
+				}
+				done.Done()
+			}
+		}()
+	}
+	defer func() {
+		close(jobs)
+		workerGroup.Wait()
+	}()
+
+	var results []sampleGradient
+	gradients := func(batch [][]mat.Vector, grads []*mat.Dense) float64 {
+		results = slices.Grow(results[:0], len(batch))[:len(batch)]
+		for start := 0; start < len(batch); start += chunksize {
+			end := min(start+chunksize, len(batch))
+			done.Add(1)
+			jobs <- gradientJob{Model: tc.Model, data: batch[start:end], results: results[start:end]}
+		}
+		done.Wait()
+		batcherror := 0.0
+		for _, sample := range results {
+			addGradients(grads, sample.nodes, sample.deltas)
+			batcherror += sample.err
+		}
+		return batcherror
+	}
+	batch := tc.BatchSize
+	if batch < 1 {
+		batch = workers * chunksize
+	}
+	return tc.train(ctx, trainingSet, iterations, batch, lrate, gradients, extra)
+}
@@ -0,0 +1,137 @@
+package goregression
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSynchronous(t *testing.T) {
+	gen := rand.New(rand.NewPCG(71, 72))
+	set := make([][]mat.Vector, 23)
+	for i := range set {
+		set[i] = []mat.Vector{
+			mat.NewVecDense(3, genN(gen, 3)),
+			mat.NewVecDense(2, genN(gen, 2)),
+		}
+	}
+	start := NewModel(rand.New(rand.NewPCG(73, 74)), Tanh, Sigmoid, 3, 8, 5, 2)
+	for _, batch := range []int{len(set), 5} {
+		expect := TrainingContext{Model: start.Clone(), Optimizer: NewAdam(), BatchSize: batch}
+		expect.Train(set, 20, 0.01, nil)
+		for _, workers := range []int{1, 3, 8} {
+			for _, chunk := range []int{1, 4} {
+				t.Run(fmt.Sprintf("batch %d workers %d chunk %d", batch, workers, chunk), func(t *testing.T) {
+					train := TrainingContext{
+						Model:     start.Clone(),
+						Optimizer: NewAdam(),
+						BatchSize: batch,
+						Parallel:  Synchronous,
+					}
+					train.TrainChunked(set, 20, workers, chunk, 0.01, nil)
+					sameWeights(t, expect.Model, train.Model)
+				})
+			}
+		}
+	}
+}
+
+func TestHogwild(t *testing.T) {
+	train := TrainingContext{
+		Model:    NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1),
+		Parallel: Hogwild,
+	}
+	var batches int
+	train.Callbacks = []Callback{CallbackFuncs{BatchEnd: func(Metrics) { batches++ }}}
+	history, err := train.TrainChunkedContext(context.Background(), xorSet, 3000, 4, 1, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Epochs) != 3000 || batches != 3000*len(xorSet) {
+		t.Fatalf("%d epochs, %d batches", len(history.Epochs), batches)
+	}
+	if final := history.Epochs[len(history.Epochs)-1].TrainLoss; final > 0.05 {
+		t.Errorf("final error %f", final)
+	}
+	for _, test := range xorSet {
+		if got := train.Predict(test[0]).AtVec(0); math.Round(got) != test[1].AtVec(0) {
+			t.Errorf("XOR(%v) = %f, want %v", mat.Formatted(test[0].T()), got, test[1].AtVec(0))
+		}
+	}
+}
+
+func TestHogwildOptimizer(t *testing.T) {
+	train := TrainingContext{
+		Model:     NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1),
+		Optimizer: NewAdam(),
+		Parallel:  Hogwild,
+	}
+	if _, err := train.TrainChunkedContext(context.Background(), xorSet, 5, 1, 1, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	// a single worker takes every step
+	if steps := train.Optimizer.(*Adam).steps; !slices.Equal(steps, []int{20, 20}) {
+		t.Errorf("Optimizer steps %v, want [20 20]", steps)
+	}
+	if steps := train.Checkpoint().Optimizer.(*Adam).steps; !slices.Equal(steps, []int{20, 20}) {
+		t.Errorf("Checkpoint steps %v, want [20 20]", steps)
+	}
+}
+
+// plainSGD is an Optimizer from outside the package, which cannot be copied.
+type plainSGD struct{}
+
+func (plainSGD) Init([]mat.Mutable) {}
+
+func (plainSGD) Update(layer int, weights mat.Mutable, gradient mat.Matrix, lrate float64) {
+	eachWeight(weights, gradient, func(r, c int, w, g float64) float64 { return w - lrate*g })
+}
+
+func TestHogwildForeignOptimizer(t *testing.T) {
+	train := TrainingContext{
+		Model:     NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1),
+		Optimizer: plainSGD{},
+		Parallel:  Hogwild,
+	}
+	if _, err := train.TrainChunkedContext(context.Background(), xorSet, 5, 2, 1, 0.1); err == nil {
+		t.Error("Hogwild accepted an Optimizer it cannot copy")
+	}
+	// the other modes share it
+	train.Parallel = Asynchronous
+	if _, err := train.TrainChunkedContext(context.Background(), xorSet, 5, 2, 1, 0.1); err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkHogwildTraining(b *testing.B) {
+	gen := rand.New(rand.NewPCG(5987, 2908))
+	randTest := make([][]mat.Vector, 50)
+	inputsize := 10
+	outputsize := 5
+	for i := range randTest {
+		randTest[i] = []mat.Vector{
+			mat.NewVecDense(inputsize, genN(gen, inputsize)),
+			mat.NewVecDense(outputsize, genN(gen, outputsize)),
+		}
+	}
+	// the same grid as BenchmarkChunkTraining so the two can be compared
+	for workers := 6; workers <= 9; workers++ {
+		for chunk := 8; chunk <= 15; chunk++ {
+			b.Run(fmt.Sprintf("workers %d chunks %d", workers, chunk), func(b *testing.B) {
+				train := TrainingContext{
+					Model:    NewModel(rand.New(rand.NewPCG(3453, 9988)), Tanh, Sigmoid, inputsize, 20, 20, outputsize),
+					Parallel: Hogwild,
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					train.TrainChunked(randTest, 50, workers, chunk, 0.1, nil)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package goregression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError reports where in an Activation expression parsing failed.
+type ParseError struct {
+	Expr   string
+	Offset int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("activation %q: offset %d: %v", e.Expr, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// expressionParser is a recursive-descent parser for the grammar
+//
+//	expr   = ident [ "(" [ arg { "," arg } ] ")" ]
+//	arg    = number | expr
+//	number = float accepted by strconv.ParseFloat, including NaN and ±Inf
+type expressionParser struct {
+	text string
+	pos  int
+}
+
+func (p *expressionParser) fail(offset int, format string, args ...any) error {
+	return &ParseError{Expr: p.text, Offset: offset, Err: fmt.Errorf(format, args...)}
+}
+
+func (p *expressionParser) parse() (Activation, error) {
+	a, err := p.expr()
+	if err != nil {
+		return Activation{}, err
+	}
+	p.space()
+	if p.pos != len(p.text) {
+		return Activation{}, p.fail(p.pos, "unexpected %q after expression", p.text[p.pos:])
+	}
+	return a, nil
+}
+
+func (p *expressionParser) space() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *expressionParser) peek() byte {
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+	return 0
+}
+
+func isIdentByte(b byte, first bool) bool {
+	switch {
+	case b == '_', 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z':
+		return true
+	case '0' <= b && b <= '9':
+		return !first
+	}
+	return false
+}
+
+func (p *expressionParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.text) && isIdentByte(p.text[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *expressionParser) expr() (Activation, error) {
+	p.space()
+	start := p.pos
+	name := p.ident()
+	if name == "" {
+		if p.pos == len(p.text) {
+			return Activation{}, p.fail(p.pos, "expected Activation name, got end of input")
+		}
+		return Activation{}, p.fail(p.pos, "expected Activation name, got %q", p.peek())
+	}
+	parser, ok := lookupActivation(name)
+	if !ok {
+		return Activation{}, p.fail(start, "unrecognized Activation %s", name)
+	}
+	var args []ActivationArg
+	p.space()
+	if p.peek() == '(' {
+		var err error
+		if args, err = p.args(); err != nil {
+			return Activation{}, err
+		}
+	}
+	a, err := parser(args)
+	if err != nil {
+		return Activation{}, &ParseError{Expr: p.text, Offset: start, Err: fmt.Errorf("%s: %w", name, err)}
+	}
+	return a, nil
+}
+
+func (p *expressionParser) args() ([]ActivationArg, error) {
+	p.pos++ // (
+	args := []ActivationArg{}
+	p.space()
+	if p.peek() == ')' {
+		p.pos++
+		return args, nil
+	}
+	for {
+		arg, err := p.arg()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		p.space()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return args, nil
+		case 0:
+			return nil, p.fail(p.pos, "expected ',' or ')', got end of input")
+		default:
+			return nil, p.fail(p.pos, "expected ',' or ')', got %q", p.peek())
+		}
+	}
+}
+
+func (p *expressionParser) arg() (ActivationArg, error) {
+	p.space()
+	start := p.pos
+	if b := p.peek(); isIdentByte(b, true) {
+		name := p.ident()
+		p.space()
+		if (name == "NaN" || name == "Inf") && p.peek() != '(' {
+			number, _ := strconv.ParseFloat(name, 64)
+			return ActivationArg{Number: number}, nil
+		}
+		p.pos = start
+		a, err := p.expr()
+		if err != nil {
+			return ActivationArg{}, err
+		}
+		return ActivationArg{Activation: &a}, nil
+	}
+	return p.number()
+}
+
+func (p *expressionParser) number() (ActivationArg, error) {
+	start := p.pos
+	if b := p.peek(); b == '+' || b == '-' {
+		p.pos++
+		if strings.HasPrefix(p.text[p.pos:], "Inf") {
+			p.pos += len("Inf")
+		}
+	}
+	for p.pos < len(p.text) && strings.IndexByte("0123456789.eE", p.text[p.pos]) >= 0 {
+		if b := p.text[p.pos]; (b == 'e' || b == 'E') && p.pos+1 < len(p.text) && (p.text[p.pos+1] == '+' || p.text[p.pos+1] == '-') {
+			p.pos++
+		}
+		p.pos++
+	}
+	text := p.text[start:p.pos]
+	if text == "" {
+		if p.pos == len(p.text) {
+			return ActivationArg{}, p.fail(start, "expected argument, got end of input")
+		}
+		return ActivationArg{}, p.fail(start, "expected argument, got %q", p.peek())
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return ActivationArg{}, p.fail(start, "invalid number %q", text)
+	}
+	return ActivationArg{Number: number}, nil
+}
@@ -0,0 +1,122 @@
+package goregression
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestShowPrecision(t *testing.T) {
+	for _, a := range []Activation{
+		Linear(0.1),
+		Linear(1.0 / 3),
+		Scale(math.Pi, Strech(-1e-300, Linear(math.MaxFloat64))),
+		Strech(2, Scale(3, Strech(4, Tanh))),
+		Linear(math.Inf(-1)),
+		Linear(math.Copysign(0, -1)),
+	} {
+		parsed, err := matchActivation(a.Show())
+		if err != nil {
+			t.Fatalf("%s: %v", a, err)
+		}
+		if parsed.Show() != a.Show() {
+			t.Errorf("round trip: got %s, want %s", parsed, a)
+		}
+		if got, want := parsed.Activate(0.7), a.Activate(0.7); got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+			t.Errorf("%s: got %v, want %v", a, got, want)
+		}
+	}
+}
+
+func TestParseErrorOffset(t *testing.T) {
+	for _, test := range []struct {
+		expr   string
+		offset int
+	}{
+		{"Nope", 0},
+		{"Scale(2, Nope)", 9},
+		{"Scale(2, Strech(x, Tanh))", 16},
+		{"Scale(2, Tanh", 13},
+		{"Tanh extra", 5},
+		{"Linear(1e)", 7},
+		{"Scale(2, Strech(3))", 9},
+	} {
+		_, err := matchActivation(test.expr)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected ParseError, got %v", test.expr, err)
+			continue
+		}
+		if perr.Offset != test.offset {
+			t.Errorf("%q: offset %d, want %d (%v)", test.expr, perr.Offset, test.offset, err)
+		}
+	}
+}
+
+// buildActivation turns fuzzer bytes into a nested Activation expression.
+func buildActivation(program []byte, params []float64) Activation {
+	if len(program) == 0 {
+		return Tanh
+	}
+	param := params[len(program)%len(params)]
+	switch program[0] % 7 {
+	case 0:
+		return Tanh
+	case 1:
+		return Sigmoid
+	case 2:
+		return ReLU
+	case 3:
+		return BiLn
+	case 4:
+		return Linear(param)
+	case 5:
+		return Scale(param, buildActivation(program[1:], params))
+	default:
+		return Strech(param, buildActivation(program[1:], params))
+	}
+}
+
+func FuzzShowRoundTrip(f *testing.F) {
+	f.Add([]byte{5, 6, 4}, 2.0, 0.1)
+	f.Add([]byte{6, 6, 6, 5, 0}, -1e-7, 3.5e10)
+	f.Add([]byte{4}, math.Inf(1), math.NaN())
+	f.Fuzz(func(t *testing.T, program []byte, a, b float64) {
+		if len(program) > 64 {
+			program = program[:64]
+		}
+		act := buildActivation(program, []float64{a, b})
+		parsed, err := matchActivation(act.Show())
+		if err != nil {
+			t.Fatalf("%s: %v", act, err)
+		}
+		if parsed.Show() != act.Show() {
+			t.Fatalf("round trip: got %s, want %s", parsed, act)
+		}
+		for _, x := range []float64{-2, 0, 0.5} {
+			got, want := parsed.Activate(x), act.Activate(x)
+			if math.Float64bits(got) != math.Float64bits(want) && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Fatalf("%s(%v): got %v, want %v", act, x, got, want)
+			}
+		}
+	})
+}
+
+func FuzzMatchActivation(f *testing.F) {
+	for _, seed := range []string{"Tanh", "Scale(2, Strech(3, Linear(1)))", "Linear(-Inf)", "Scale(1e-3,ReLU)", "Strech(,)"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		act, err := matchActivation(text)
+		if err != nil {
+			return
+		}
+		again, err := matchActivation(act.Show())
+		if err != nil {
+			t.Fatalf("%q parsed to %s which does not parse: %v", text, act, err)
+		}
+		if again.Show() != act.Show() {
+			t.Fatalf("%q: %s != %s", text, again, act)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package goregression
+
+import "gonum.org/v1/gonum/mat"
+
+// Predictor runs a Model with buffers that are reused by every call, so
+// predictions make no heap allocations. A Predictor is not safe for
+// concurrent use; give each goroutine its own. It keeps the *Model it was
+// made from and reads its weights on every call, so updates Train makes in
+// place show up in later predictions. Training that replaces the Model of a
+// TrainingContext, as TrainChunked and Restore do, needs a new Predictor.
+type Predictor struct {
+	model *Model
+	// nodes[l] is the input of layer l with a trailing 1, and hidden[l] the
+	// part of nodes[l+1] written by layer l.
+	nodes  []*mat.VecDense
+	hidden []*mat.VecDense
+	pre    *mat.VecDense
+	output *mat.VecDense
+}
+
+func NewPredictor(m *Model) *Predictor {
+	p := &Predictor{model: m}
+	for _, w := range m.Weights {
+		_, c := w.Dims()
+		nodes := mat.NewVecDense(c, nil)
+		nodes.SetVec(c-1, 1)
+		p.nodes = append(p.nodes, nodes)
+	}
+	for _, nodes := range p.nodes[1:] {
+		p.hidden = append(p.hidden, nodes.SliceVec(0, nodes.Len()-1).(*mat.VecDense))
+	}
+	p.pre = mat.NewVecDense(m.OutputSize(), nil)
+	p.output = mat.NewVecDense(m.OutputSize(), nil)
+	return p
+}
+
+// Predict is Model.Predict. The returned vector belongs to the Predictor and
+// is overwritten by the next call.
+func (p *Predictor) Predict(input mat.Vector) mat.Vector {
+	p.PredictInto(p.output, input)
+	return p.output
+}
+
+// PredictInto writes the prediction for input into dst, which must have the
+// Model's output size.
+func (p *Predictor) PredictInto(dst *mat.VecDense, input mat.Vector) {
+	m := p.model
+	if input.Len()+1 != p.nodes[0].Len() {
+		panic("incorrect input size")
+	}
+	if dst.Len() != m.OutputSize() {
+		panic("incorrect output size")
+	}
+	for i := 0; i < input.Len(); i++ {
+		p.nodes[0].SetVec(i, input.AtVec(i))
+	}
+	last := len(m.Weights) - 1
+	for layer, weights := range m.Weights[:last] {
+		pre := p.hidden[layer]
+		pre.MulVec(weights, p.nodes[layer])
+		activation := m.activation(layer)
+		data := pre.RawVector().Data
+		for i, f := range data {
+			data[i] = activation.Activate(f)
+		}
+	}
+	p.pre.MulVec(m.Weights[last], p.nodes[last])
+	if m.VectorOutput != nil {
+		// strided views such as a column of a Dense go through p.output
+		if raw := dst.RawVector(); raw.Inc == 1 {
+			m.VectorOutput.Activate(raw.Data[:dst.Len()], p.pre.RawVector().Data)
+			return
+		}
+		m.VectorOutput.Activate(p.output.RawVector().Data, p.pre.RawVector().Data)
+		for i := 0; i < dst.Len(); i++ {
+			dst.SetVec(i, p.output.AtVec(i))
+		}
+		return
+	}
+	activation := m.activation(last)
+	for i := 0; i < dst.Len(); i++ {
+		dst.SetVec(i, activation.Activate(p.pre.AtVec(i)))
+	}
+}
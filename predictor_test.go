@@ -0,0 +1,66 @@
+package goregression
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPredictor(t *testing.T) {
+	gen := rand.New(rand.NewPCG(91, 92))
+	sparse := NewModel(gen, ReLU, Linear(1), 5, 8, 4)
+	sparse.VectorOutput = &Sparsemax
+	for name, model := range map[string]*Model{
+		"tanh":      NewModel(gen, Tanh, Sigmoid, 5, 8, 8, 4),
+		"layers":    NewModelLayers(gen, []Activation{GELU, Swish, Softplus}, 5, 6, 7, 4),
+		"softmax":   {Weights: NewModel(gen, Tanh, Linear(1), 5, 8, 4).Weights, Internal: Tanh, VectorOutput: &Softmax},
+		"sparsemax": sparse,
+	} {
+		p := NewPredictor(model)
+		input := mat.NewVecDense(5, nil)
+		dst := mat.NewVecDense(4, nil)
+		col := mat.NewDense(4, 3, nil).ColView(1).(*mat.VecDense)
+		for i := 0; i < 5; i++ {
+			input.SetRawVector(mat.NewVecDense(5, genN(gen, 5)).RawVector())
+			want := model.Predict(input)
+			p.PredictInto(dst, input)
+			if !mat.Equal(dst, want) || !mat.Equal(p.Predict(input), want) {
+				t.Errorf("%s: got %v, want %v", name, mat.Formatted(dst.T()), mat.Formatted(want.T()))
+			}
+			p.PredictInto(col, input)
+			if !mat.Equal(col, want) {
+				t.Errorf("%s: column got %v, want %v", name, mat.Formatted(col.T()), mat.Formatted(want.T()))
+			}
+		}
+		if allocs := testing.AllocsPerRun(100, func() { p.PredictInto(dst, input) }); allocs != 0 {
+			t.Errorf("%s: PredictInto made %v allocations", name, allocs)
+		}
+		if allocs := testing.AllocsPerRun(100, func() { p.PredictInto(col, input) }); allocs != 0 {
+			t.Errorf("%s: PredictInto a column made %v allocations", name, allocs)
+		}
+		if allocs := testing.AllocsPerRun(100, func() { p.Predict(input) }); allocs != 0 {
+			t.Errorf("%s: Predict made %v allocations", name, allocs)
+		}
+	}
+}
+
+func BenchmarkPredictor(b *testing.B) {
+	gen := rand.New(rand.NewPCG(93, 94))
+	model := NewModel(gen, Tanh, Sigmoid, 10, 20, 20, 5)
+	input := mat.NewVecDense(10, genN(gen, 10))
+	b.Run("Model", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			model.Predict(input)
+		}
+	})
+	b.Run("Predictor", func(b *testing.B) {
+		p := NewPredictor(model)
+		dst := mat.NewVecDense(5, nil)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.PredictInto(dst, input)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package goregression
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type pinball []float64
+
+// Pinball is the quantile loss. Output i of the Model is trained to predict
+// quantile quantiles[i] of the target. A single target value is shared by all
+// outputs, otherwise the target must have one value per quantile.
+func Pinball(quantiles ...float64) Loss {
+	for _, q := range quantiles {
+		if !(q > 0 && q < 1) {
+			panic(fmt.Sprintf("quantile %v is not in (0, 1)", q))
+		}
+	}
+	return pinball(slices.Clone(quantiles))
+}
+
+func (p pinball) target(target mat.Vector, i int) float64 {
+	if target.Len() == 1 {
+		return target.AtVec(0)
+	}
+	return target.AtVec(i)
+}
+
+func (p pinball) validTarget(outputs, target int) bool {
+	return target == 1 || target == outputs
+}
+
+func (p pinball) check(outputs int) {
+	if outputs != len(p) {
+		panic(fmt.Sprintf("%s needs %d outputs, model has %d", p, len(p), outputs))
+	}
+}
+
+func (p pinball) Value(out, target mat.Vector) float64 {
+	p.check(out.Len())
+	sum := 0.0
+	for i, q := range p {
+		diff := p.target(target, i) - out.AtVec(i)
+		if diff >= 0 {
+			sum += q * diff
+		} else {
+			sum += (q - 1) * diff
+		}
+	}
+	return sum / float64(len(p))
+}
+
+func (p pinball) Gradient(dst []float64, out, target mat.Vector) {
+	p.check(len(dst))
+	for i, q := range p {
+		t, o := p.target(target, i), out.AtVec(i)
+		switch {
+		case t > o:
+			dst[i] = -q
+		case t < o:
+			dst[i] = 1 - q
+		default:
+			dst[i] = 0
+		}
+	}
+}
+
+func (p pinball) String() string {
+	names := make([]string, len(p))
+	for i, q := range p {
+		names[i] = formatFloat(q)
+	}
+	return "Pinball(" + strings.Join(names, ", ") + ")"
+}
+
+// QuantileRegressor is a Model whose outputs predict the given quantiles of a
+// single target, in the same order. Train it with Pinball(Quantiles...).
+type QuantileRegressor struct {
+	*Model
+	Quantiles []float64
+}
+
+type QuantilePrediction struct {
+	Name     string
+	Quantile float64
+	Value    float64
+}
+
+func (q QuantileRegressor) Loss() Loss {
+	return Pinball(q.Quantiles...)
+}
+
+// PredictQuantiles returns the predictions ordered by quantile, named like
+// "q0.9". Independently trained outputs can cross, so the values are
+// rearranged to be non-decreasing in the quantile. It panics when the Model
+// does not have one output per quantile.
+func (q QuantileRegressor) PredictQuantiles(input mat.Vector) []QuantilePrediction {
+	if q.OutputSize() != len(q.Quantiles) {
+		panic(fmt.Sprintf("QuantileRegressor has %d quantiles for %d outputs", len(q.Quantiles), q.OutputSize()))
+	}
+	out := q.Predict(input)
+	predictions := make([]QuantilePrediction, len(q.Quantiles))
+	values := make([]float64, len(q.Quantiles))
+	for i, quantile := range q.Quantiles {
+		predictions[i] = QuantilePrediction{
+			Name:     "q" + formatFloat(quantile),
+			Quantile: quantile,
+		}
+		values[i] = out.AtVec(i)
+	}
+	slices.SortFunc(predictions, func(a, b QuantilePrediction) int {
+		switch {
+		case a.Quantile < b.Quantile:
+			return -1
+		case a.Quantile > b.Quantile:
+			return 1
+		}
+		return 0
+	})
+	slices.Sort(values)
+	for i := range predictions {
+		predictions[i].Value = values[i]
+	}
+	return predictions
+}
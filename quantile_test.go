@@ -0,0 +1,75 @@
+package goregression
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestQuantileRegression(t *testing.T) {
+	gen := rand.New(rand.NewPCG(21, 22))
+	var data [][]mat.Vector
+	for i := 0; i < 400; i++ {
+		x := gen.Float64()
+		y := x + gen.Float64()*2 - 1
+		data = append(data, []mat.Vector{mat.NewVecDense(1, []float64{x}), mat.NewVecDense(1, []float64{y})})
+	}
+	regressor := QuantileRegressor{
+		Model:     NewModel(rand.New(rand.NewPCG(23, 24)), Linear(1), Linear(1), 1, 3),
+		Quantiles: []float64{0.9, 0.1, 0.5},
+	}
+	train := TrainingContext{Model: regressor.Model, Loss: regressor.Loss()}
+	train.Train(data, 200, 0.01, nil)
+
+	below := map[string]int{}
+	for _, sample := range data {
+		predictions := regressor.PredictQuantiles(sample[0])
+		if predictions[0].Name != "q0.1" || predictions[1].Name != "q0.5" || predictions[2].Name != "q0.9" {
+			t.Fatalf("predictions not ordered by quantile: %+v", predictions)
+		}
+		for _, p := range predictions {
+			if sample[1].AtVec(0) < p.Value {
+				below[p.Name]++
+			}
+		}
+	}
+	for name, want := range map[string]float64{"q0.1": 0.1, "q0.5": 0.5, "q0.9": 0.9} {
+		got := float64(below[name]) / float64(len(data))
+		if math.Abs(got-want) > 0.06 {
+			t.Errorf("%s covers %.3f of the targets, want about %.1f", name, got, want)
+		}
+	}
+}
+
+func TestQuantilesDoNotCross(t *testing.T) {
+	regressor := QuantileRegressor{
+		Model: &Model{
+			Weights:  []mat.Mutable{mat.NewDense(3, 2, []float64{1, 0, 0, 0, -1, 0})},
+			Output:   Linear(1),
+			Internal: Linear(1),
+		},
+		Quantiles: []float64{0.1, 0.5, 0.9},
+	}
+	// at x = 2 the raw outputs are 2, 0, -2: fully crossed
+	predictions := regressor.PredictQuantiles(mat.NewVecDense(1, []float64{2}))
+	for i, want := range []float64{-2, 0, 2} {
+		if predictions[i].Value != want {
+			t.Errorf("%s = %v, want %v", predictions[i].Name, predictions[i].Value, want)
+		}
+	}
+}
+
+func TestQuantilesMismatch(t *testing.T) {
+	regressor := QuantileRegressor{
+		Model:     NewModel(rand.New(rand.NewPCG(1, 2)), Tanh, Linear(1), 1, 3, 2),
+		Quantiles: []float64{0.1, 0.5, 0.9},
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic predicting 3 quantiles from 2 outputs")
+		}
+	}()
+	regressor.PredictQuantiles(mat.NewVecDense(1, []float64{2}))
+}
@@ -0,0 +1,119 @@
+package goregression
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ActivationArg is one argument of a parameterized Activation expression such
+// as Scale(2, Tanh). Activation is nil when the argument is a number.
+type ActivationArg struct {
+	Number     float64
+	Activation *Activation
+}
+
+func (a ActivationArg) String() string {
+	if a.Activation != nil {
+		return a.Activation.String()
+	}
+	return formatFloat(a.Number)
+}
+
+// ActivationParser builds an Activation from the arguments found between the
+// parentheses of its name. Activations without parentheses get nil args.
+type ActivationParser func(args []ActivationArg) (Activation, error)
+
+var (
+	registryMu  sync.RWMutex
+	activations = map[string]ActivationParser{}
+)
+
+// RegisterActivation makes an Activation available to UnmarshalJSON under the
+// given name. The Show function of the built Activation should produce text
+// that parses back to an equivalent Activation. Like sql.Register, it panics if
+// called twice with the same name or with a nil parser.
+func RegisterActivation(name string, parser ActivationParser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if parser == nil {
+		panic("goregression: RegisterActivation parser is nil")
+	}
+	if _, dup := activations[name]; dup {
+		panic("goregression: RegisterActivation called twice for " + name)
+	}
+	activations[name] = parser
+}
+
+func lookupActivation(name string) (ActivationParser, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	parser, ok := activations[name]
+	return parser, ok
+}
+
+// Constant is the parser for an Activation that takes no arguments.
+func Constant(a Activation) ActivationParser {
+	return func(args []ActivationArg) (Activation, error) {
+		if len(args) != 0 {
+			return Activation{}, fmt.Errorf("expected no arguments, got %d", len(args))
+		}
+		return a, nil
+	}
+}
+
+// Parameterized is the parser for an Activation built from count numbers.
+func Parameterized(count int, build func(params ...float64) Activation) ActivationParser {
+	return func(args []ActivationArg) (Activation, error) {
+		if len(args) != count {
+			return Activation{}, fmt.Errorf("expected %d arguments, got %d", count, len(args))
+		}
+		params := make([]float64, count)
+		for i, arg := range args {
+			if arg.Activation != nil {
+				return Activation{}, fmt.Errorf("argument %d: expected a number, got %s", i+1, arg)
+			}
+			params[i] = arg.Number
+		}
+		return build(params...), nil
+	}
+}
+
+// Wrapper is the parser for an Activation built from a number and another
+// Activation, like Scale and Strech.
+func Wrapper(build func(factor float64, general Activation) Activation) ActivationParser {
+	return func(args []ActivationArg) (Activation, error) {
+		if len(args) != 2 {
+			return Activation{}, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+		if args[0].Activation != nil {
+			return Activation{}, fmt.Errorf("argument 1: expected a number, got %s", args[0])
+		}
+		if args[1].Activation == nil {
+			return Activation{}, fmt.Errorf("argument 2: expected an Activation, got %s", args[1])
+		}
+		return build(args[0].Number, *args[1].Activation), nil
+	}
+}
+
+func init() {
+	RegisterActivation("Tanh", Constant(Tanh))
+	RegisterActivation("Sigmoid", Constant(Sigmoid))
+	RegisterActivation("BiLn", Constant(BiLn))
+	RegisterActivation("ReLU", Constant(ReLU))
+	RegisterActivation("Linear", Parameterized(1, func(params ...float64) Activation {
+		return Linear(params[0])
+	}))
+	RegisterActivation("Scale", Wrapper(Scale))
+	RegisterActivation("Strech", Wrapper(Strech))
+	RegisterActivation("LeakyReLU", Parameterized(1, func(params ...float64) Activation {
+		return LeakyReLU(params[0])
+	}))
+	RegisterActivation("ELU", Parameterized(1, func(params ...float64) Activation {
+		return ELU(params[0])
+	}))
+	RegisterActivation("SELU", Constant(SELU))
+	RegisterActivation("GELU", Constant(GELU))
+	RegisterActivation("Softplus", Constant(Softplus))
+	RegisterActivation("Swish", Constant(Swish))
+	RegisterActivation("Mish", Constant(Mish))
+}
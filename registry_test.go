@@ -0,0 +1,65 @@
+package goregression
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func power(p float64) Activation {
+	return Activation{
+		Activate:   func(f float64) float64 { return math.Pow(f, p) },
+		Derivative: func(f float64) float64 { return p * math.Pow(f, p-1) },
+		Show:       func() string { return fmt.Sprintf("TestPower(%v)", p) },
+	}
+}
+
+func init() {
+	RegisterActivation("TestPower", Parameterized(1, func(params ...float64) Activation {
+		return power(params[0])
+	}))
+}
+
+func TestRegisteredActivationJSON(t *testing.T) {
+	model := NewModel(rand.New(rand.NewPCG(7, 8)), power(3), Scale(2, power(1.5)), 2, 3, 1)
+	text, err := json.Marshal(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Model
+	if err := json.Unmarshal(text, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Internal.Show() != "TestPower(3)" {
+		t.Errorf("internal: got %s", loaded.Internal)
+	}
+	if got := loaded.Output.Activate(4); got != 16 {
+		t.Errorf("output: Scale(2, TestPower(1.5))(4) = %v, want 16", got)
+	}
+}
+
+func TestMatchActivationNested(t *testing.T) {
+	a, err := matchActivation("Scale(2.000000, Strech(3.000000, Scale(0.500000, Linear(1.000000))))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Activate(1); got != 3 {
+		t.Errorf("got %v, want 3", got)
+	}
+	for _, bad := range []string{"Unknown", "Linear()", "Linear(Tanh)", "Scale(1, 2)", "Tanh(1)", "Scale(1, Tanh"} {
+		if _, err := matchActivation(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestRegisterActivationDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic registering Tanh twice")
+		}
+	}()
+	RegisterActivation("Tanh", Constant(Tanh))
+}
@@ -0,0 +1,54 @@
+package goregression
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Regularization penalizes large weights with
+//
+//	L1·Σ|w| + L2/2·Σw²
+//
+// added to the error of every training sample, and its gradient to theirs.
+// Biases, the last column of every weight matrix, are left out unless
+// IncludeBias is set.
+type Regularization struct {
+	L1          float64
+	L2          float64
+	IncludeBias bool
+}
+
+// ElasticNet mixes L1 and L2 penalties: ratio 1 is pure L1, 0 pure L2.
+func ElasticNet(strength, ratio float64) Regularization {
+	return Regularization{L1: strength * ratio, L2: strength * (1 - ratio)}
+}
+
+// regularize adds the gradient of the penalty for n samples to grads and
+// returns that penalty.
+func (r Regularization) regularize(grads []*mat.Dense, weights []mat.Mutable, n float64) float64 {
+	if r.L1 == 0 && r.L2 == 0 {
+		return 0
+	}
+	penalty := 0.0
+	for layer, w := range weights {
+		R, C := w.Dims()
+		if !r.IncludeBias {
+			C--
+		}
+		for row := 0; row < R; row++ {
+			for col := 0; col < C; col++ {
+				v := w.At(row, col)
+				penalty += r.L1*math.Abs(v) + r.L2/2*v*v
+				sign := 0.0
+				if v > 0 {
+					sign = 1
+				} else if v < 0 {
+					sign = -1
+				}
+				grads[layer].Set(row, col, grads[layer].At(row, col)+n*(r.L1*sign+r.L2*v))
+			}
+		}
+	}
+	return n * penalty
+}
@@ -0,0 +1,84 @@
+package goregression
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRegularize(t *testing.T) {
+	weights := []mat.Mutable{mat.NewDense(1, 3, []float64{1, -2, 3}), mat.NewDense(1, 2, []float64{0, -1})}
+	for _, test := range []struct {
+		r       Regularization
+		penalty float64
+		want    []float64
+	}{
+		{Regularization{}, 0, []float64{0, 0, 0, 0, 0}},
+		// 2 samples of 0.1·(1+2) + 0.1·(1+4), biases left alone
+		{Regularization{L1: 0.1, L2: 0.2}, 1.6, []float64{0.6, -1, 0, 0, 0}},
+		{Regularization{L1: 0.1, L2: 0.2, IncludeBias: true}, 1.6 + 2*(0.3+0.9) + 2*(0.1+0.1), []float64{0.6, -1, 1.4, 0, -0.6}},
+	} {
+		grads := []*mat.Dense{mat.NewDense(1, 3, nil), mat.NewDense(1, 2, nil)}
+		penalty := test.r.regularize(grads, weights, 2)
+		got := []float64{grads[0].At(0, 0), grads[0].At(0, 1), grads[0].At(0, 2), grads[1].At(0, 0), grads[1].At(0, 1)}
+		if math.Abs(penalty-test.penalty) > 1e-12 {
+			t.Errorf("%+v: penalty %v, want %v", test.r, penalty, test.penalty)
+		}
+		for i := range got {
+			if math.Abs(got[i]-test.want[i]) > 1e-12 {
+				t.Errorf("%+v: gradients %v, want %v", test.r, got, test.want)
+				break
+			}
+		}
+	}
+
+	if r := ElasticNet(0.5, 0.2); math.Abs(r.L1-0.1) > 1e-12 || math.Abs(r.L2-0.4) > 1e-12 || r.IncludeBias {
+		t.Errorf("ElasticNet(0.5, 0.2) = %+v", r)
+	}
+}
+
+func TestRegularizedTraining(t *testing.T) {
+	run := func(mode string, r Regularization, epochs int, lrate float64) (*TrainingContext, *History) {
+		train := &TrainingContext{
+			Model:          NewModel(rand.New(rand.NewPCG(77, 1234)), Linear(1), Linear(1), 1, 3, 1),
+			Regularization: r,
+		}
+		history, err := trainMode(train, mode, doubleSet, epochs, 1, lrate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return train, history
+	}
+	weightNorm := func(m *Model) float64 {
+		total := 0.0
+		for _, w := range m.Weights {
+			r, c := w.Dims()
+			for i := 0; i < r; i++ {
+				for j := 0; j < c-1; j++ {
+					total += w.At(i, j) * w.At(i, j)
+				}
+			}
+		}
+		return math.Sqrt(total)
+	}
+
+	r := Regularization{L1: 0.01, L2: 0.5}
+	for _, mode := range []string{"Train", "Asynchronous", "Synchronous", "Hogwild"} {
+		// with a zero rate the weights never move, so the reported error only
+		// differs by the penalty of every sample
+		plain, before := run(mode, Regularization{}, 1, 0)
+		_, after := run(mode, r, 1, 0)
+		penalty := r.regularize([]*mat.Dense{mat.NewDense(3, 2, nil), mat.NewDense(1, 4, nil)}, plain.Weights, float64(len(doubleSet)))
+		if diff := after.Epochs[0].TrainLoss - before.Epochs[0].TrainLoss; math.Abs(diff-penalty) > 1e-9 {
+			t.Errorf("%s: error grew by %v, want %v", mode, diff, penalty)
+		}
+
+		plain, _ = run(mode, Regularization{}, 100, 0.01)
+		regularized, _ := run(mode, r, 100, 0.01)
+		if plain.HasNonFinite() || regularized.HasNonFinite() || !(weightNorm(regularized.Model) < weightNorm(plain.Model)) {
+			t.Errorf("%s: weight norm %v regularized, %v without", mode, weightNorm(regularized.Model), weightNorm(plain.Model))
+		}
+	}
+}
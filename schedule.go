@@ -0,0 +1,152 @@
+package goregression
+
+import "math"
+
+// Schedule picks the learning rate for every update. Epochs and steps count
+// from 0; step counts updates across all epochs.
+type Schedule interface {
+	Rate(epoch, step int, base float64) float64
+}
+
+// EpochObserver is implemented by schedules that adapt to the training error.
+// Observe is called with the total error at the end of every epoch.
+type EpochObserver interface {
+	Observe(epoch int, err float64)
+}
+
+// StepDecay multiplies the rate by Factor every Every epochs.
+type StepDecay struct {
+	Every  int
+	Factor float64
+}
+
+func (s StepDecay) Rate(epoch, step int, base float64) float64 {
+	return base * math.Pow(s.Factor, float64(epoch/max(s.Every, 1)))
+}
+
+// ExponentialDecay multiplies the rate by Gamma every epoch.
+type ExponentialDecay struct {
+	Gamma float64
+}
+
+func (s ExponentialDecay) Rate(epoch, step int, base float64) float64 {
+	return base * math.Pow(s.Gamma, float64(epoch))
+}
+
+// CosineRestarts anneals from the base rate to Min over Period epochs, then
+// restarts. Each period is Mult times longer than the one before when Mult > 1.
+type CosineRestarts struct {
+	Period int
+	Mult   int
+	Min    float64
+}
+
+func (s CosineRestarts) Rate(epoch, step int, base float64) float64 {
+	period := max(s.Period, 1)
+	for epoch >= period {
+		epoch -= period
+		if s.Mult > 1 {
+			period *= s.Mult
+		}
+	}
+	return s.Min + (base-s.Min)*(1+math.Cos(math.Pi*float64(epoch)/float64(period)))/2
+}
+
+// LinearWarmup ramps the rate up from near zero over the first Steps updates,
+// then hands over to Then, or keeps the base rate when Then is nil.
+type LinearWarmup struct {
+	Steps int
+	Then  Schedule
+}
+
+func (s LinearWarmup) Rate(epoch, step int, base float64) float64 {
+	if step < s.Steps {
+		return base * float64(step+1) / float64(s.Steps)
+	}
+	if s.Then == nil {
+		return base
+	}
+	return s.Then.Rate(epoch, step, base)
+}
+
+// OneCycle warms up from base/InitialDiv to the base rate over the first
+// WarmupFraction of TotalSteps, then anneals down to base/(InitialDiv*FinalDiv).
+// It stays at the base rate when TotalSteps is less than 1, and zero divisors
+// take the NewOneCycle defaults.
+type OneCycle struct {
+	TotalSteps     int
+	WarmupFraction float64
+	InitialDiv     float64
+	FinalDiv       float64
+}
+
+func NewOneCycle(totalSteps int) OneCycle {
+	return OneCycle{
+		TotalSteps:     totalSteps,
+		WarmupFraction: 0.3,
+		InitialDiv:     25,
+		FinalDiv:       1e4,
+	}
+}
+
+func (s OneCycle) Rate(epoch, step int, base float64) float64 {
+	if s.TotalSteps < 1 {
+		return base
+	}
+	cosine := func(from, to, progress float64) float64 {
+		return to + (from-to)*(1+math.Cos(math.Pi*min(progress, 1)))/2
+	}
+	initialDiv, finalDiv := s.InitialDiv, s.FinalDiv
+	if initialDiv == 0 {
+		initialDiv = 25
+	}
+	if finalDiv == 0 {
+		finalDiv = 1e4
+	}
+	initial := base / initialDiv
+	warmup := s.WarmupFraction * float64(s.TotalSteps)
+	if float64(step) < warmup {
+		return cosine(initial, base, float64(step)/warmup)
+	}
+	return cosine(base, initial/finalDiv, (float64(step)-warmup)/max(float64(s.TotalSteps)-warmup, 1))
+}
+
+// ReduceOnPlateau multiplies the rate by Factor whenever the epoch error has
+// not improved by more than MinDelta for Patience epochs, down to Min.
+type ReduceOnPlateau struct {
+	Factor   float64
+	Patience int
+	MinDelta float64
+	Min      float64
+
+	scale float64
+	best  float64
+	wait  int
+	seen  bool
+}
+
+func NewReduceOnPlateau(factor float64, patience int) *ReduceOnPlateau {
+	return &ReduceOnPlateau{Factor: factor, Patience: patience}
+}
+
+func (s *ReduceOnPlateau) Rate(epoch, step int, base float64) float64 {
+	if s.scale == 0 {
+		s.scale = 1
+	}
+	return max(base*s.scale, s.Min)
+}
+
+func (s *ReduceOnPlateau) Observe(epoch int, err float64) {
+	if s.scale == 0 {
+		s.scale = 1
+	}
+	if !s.seen || err < s.best-s.MinDelta {
+		s.best, s.wait, s.seen = err, 0, true
+		return
+	}
+	s.wait++
+	if s.wait > s.Patience {
+		s.scale *= s.Factor
+		s.wait = 0
+	}
+}
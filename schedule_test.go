@@ -0,0 +1,72 @@
+package goregression
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSchedules(t *testing.T) {
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-12 }
+	for _, test := range []struct {
+		schedule    Schedule
+		epoch, step int
+		want        float64
+	}{
+		{StepDecay{Every: 10, Factor: 0.5}, 9, 0, 1},
+		{StepDecay{Every: 10, Factor: 0.5}, 25, 0, 0.25},
+		{ExponentialDecay{Gamma: 0.9}, 2, 0, 0.81},
+		{CosineRestarts{Period: 4, Min: 0.1}, 0, 0, 1},
+		{CosineRestarts{Period: 4, Min: 0.1}, 2, 0, 0.55},
+		{CosineRestarts{Period: 4, Min: 0.1}, 4, 0, 1},
+		{CosineRestarts{Period: 2, Mult: 2, Min: 0}, 4, 0, 0.5},
+		{LinearWarmup{Steps: 4}, 0, 0, 0.25},
+		{LinearWarmup{Steps: 4}, 0, 3, 1},
+		{LinearWarmup{Steps: 4, Then: ExponentialDecay{Gamma: 0.5}}, 1, 10, 0.5},
+		{NewOneCycle(100), 0, 0, 0.04},
+		{NewOneCycle(100), 0, 30, 1},
+		{NewOneCycle(100), 0, 100, 0.04 / 1e4},
+		{NewOneCycle(0), 0, 0, 1},
+		{OneCycle{TotalSteps: 100}, 0, 0, 1},
+		{OneCycle{TotalSteps: 100}, 0, 100, 0.04 / 1e4},
+		{OneCycle{TotalSteps: 100, WarmupFraction: 0.3}, 0, 0, 0.04},
+		{OneCycle{TotalSteps: 10, WarmupFraction: 1, InitialDiv: 25, FinalDiv: 1e4}, 0, 10, 1},
+	} {
+		if got := test.schedule.Rate(test.epoch, test.step, 1); !near(got, test.want) {
+			t.Errorf("%#v.Rate(%d, %d) = %v, want %v", test.schedule, test.epoch, test.step, got, test.want)
+		}
+	}
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	s := NewReduceOnPlateau(0.5, 1)
+	for epoch, err := range []float64{10, 5, 5, 5, 4, 4, 4, 4} {
+		s.Observe(epoch, err)
+	}
+	// stalled twice for more than one epoch: after the second 5 and the third 4
+	if got := s.Rate(8, 0, 1); got != 0.25 {
+		t.Errorf("rate %v, want 0.25", got)
+	}
+}
+
+func TestScheduledTraining(t *testing.T) {
+	train := TrainingContext{
+		Model:    NewModel(rand.New(rand.NewPCG(30, 34)), Tanh, Sigmoid, 2, 4, 1),
+		Schedule: StepDecay{Every: 2, Factor: 0.1},
+	}
+	chunked := TrainingContext{
+		Model:    train.Clone(),
+		Schedule: train.Schedule,
+	}
+	var rates []float64
+	train.Train(xorSet, 5, 1, func(epoch int, err float64) {
+		rates = append(rates, train.LearningRate)
+	})
+	for i, want := range []float64{1, 1, 0.1, 0.1, 0.01} {
+		if !(math.Abs(rates[i]-want) < 1e-12) {
+			t.Errorf("epoch %d rate %v, want %v", i, rates[i], want)
+		}
+	}
+	chunked.TrainChunked(xorSet, 5, 1, 1, 1, nil)
+	sameWeights(t, train.Model, chunked.Model)
+}
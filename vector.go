@@ -0,0 +1,161 @@
+package goregression
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// VectorActivation maps a whole layer at once, so each output may depend on
+// every input. It can only be used as the output stage of a Model.
+type VectorActivation struct {
+	// Activate writes the activation of src into dst, both of the same length.
+	Activate func(dst, src []float64)
+	// Jacobian fills jac with d Activate(src)[i] / d src[j] at jac(i, j).
+	Jacobian func(jac *mat.Dense, src []float64)
+	Show     func() string
+}
+
+var Softmax = VectorActivation{
+	Activate: softmax,
+	Jacobian: func(jac *mat.Dense, src []float64) {
+		s := make([]float64, len(src))
+		softmax(s, src)
+		for i := range s {
+			for j := range s {
+				d := -s[i] * s[j]
+				if i == j {
+					d += s[i]
+				}
+				jac.Set(i, j, d)
+			}
+		}
+	},
+	Show: func() string { return "Softmax" },
+}
+
+func softmax(dst, src []float64) {
+	top := slices.Max(src)
+	sum := 0.0
+	for i, f := range src {
+		dst[i] = math.Exp(f - top)
+		sum += dst[i]
+	}
+	for i := range dst {
+		dst[i] /= sum
+	}
+}
+
+var LogSoftmax = VectorActivation{
+	Activate: func(dst, src []float64) {
+		top := slices.Max(src)
+		sum := 0.0
+		for _, f := range src {
+			sum += math.Exp(f - top)
+		}
+		logsum := top + math.Log(sum)
+		for i, f := range src {
+			dst[i] = f - logsum
+		}
+	},
+	Jacobian: func(jac *mat.Dense, src []float64) {
+		s := make([]float64, len(src))
+		softmax(s, src)
+		for i := range s {
+			for j := range s {
+				d := -s[j]
+				if i == j {
+					d += 1
+				}
+				jac.Set(i, j, d)
+			}
+		}
+	},
+	Show: func() string { return "LogSoftmax" },
+}
+
+// Sparsemax is the Euclidean projection onto the probability simplex. Unlike
+// Softmax it can assign exactly zero probability to an output.
+var Sparsemax = VectorActivation{
+	Activate: func(dst, src []float64) {
+		// dst doubles as the sort buffer unless it is src itself
+		var scratch []float64
+		if len(dst) > 0 && &dst[0] != &src[0] {
+			scratch = dst
+		}
+		tau := sparsemaxThreshold(src, scratch)
+		for i, f := range src {
+			dst[i] = max(f-tau, 0)
+		}
+	},
+	Jacobian: func(jac *mat.Dense, src []float64) {
+		tau := sparsemaxThreshold(src, nil)
+		support := 0.0
+		for _, f := range src {
+			if f > tau {
+				support++
+			}
+		}
+		for i, fi := range src {
+			for j, fj := range src {
+				d := 0.0
+				if fi > tau && fj > tau {
+					d = -1 / support
+					if i == j {
+						d += 1
+					}
+				}
+				jac.Set(i, j, d)
+			}
+		}
+	},
+	Show: func() string { return "Sparsemax" },
+}
+
+// sparsemaxThreshold sorts a copy of src in scratch, allocated when nil.
+func sparsemaxThreshold(src, scratch []float64) float64 {
+	sorted := append(scratch[:0], src...)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+	cumulative, tau := 0.0, 0.0
+	for k, f := range sorted {
+		cumulative += f
+		if 1+float64(k+1)*f > cumulative {
+			tau = (cumulative - 1) / float64(k+1)
+		}
+	}
+	return tau
+}
+
+func (v VectorActivation) String() string {
+	return v.Show()
+}
+
+func (v VectorActivation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+func (v *VectorActivation) UnmarshalJSON(text []byte) error {
+	var name string
+	if err := json.Unmarshal(text, &name); err != nil {
+		return err
+	}
+	var err error
+	*v, err = matchVectorActivation(name)
+	return err
+}
+
+func matchVectorActivation(name string) (VectorActivation, error) {
+	switch name {
+	case "Softmax":
+		return Softmax, nil
+	case "LogSoftmax":
+		return LogSoftmax, nil
+	case "Sparsemax":
+		return Sparsemax, nil
+	}
+	return VectorActivation{}, fmt.Errorf("unrecognized VectorActivation: %s", name)
+}
@@ -0,0 +1,123 @@
+package goregression
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestVectorJacobian(t *testing.T) {
+	const h = 1e-6
+	src := []float64{0.3, -1.2, 0.9, 0.2}
+	n := len(src)
+	for _, v := range []VectorActivation{Softmax, LogSoftmax, Sparsemax} {
+		jac := mat.NewDense(n, n, nil)
+		v.Jacobian(jac, src)
+		up, down := make([]float64, n), make([]float64, n)
+		for j := range src {
+			shifted := append([]float64(nil), src...)
+			shifted[j] += h
+			v.Activate(up, shifted)
+			shifted[j] -= 2 * h
+			v.Activate(down, shifted)
+			for i := range src {
+				numeric := (up[i] - down[i]) / (2 * h)
+				if math.Abs(numeric-jac.At(i, j)) > 1e-6 {
+					t.Errorf("%s jacobian(%d, %d) = %v, numeric estimate %v", v, i, j, jac.At(i, j), numeric)
+				}
+			}
+		}
+	}
+}
+
+func TestSimplex(t *testing.T) {
+	src := []float64{1000, 999, -3, 0.5}
+	dst := make([]float64, len(src))
+	for _, v := range []VectorActivation{Softmax, Sparsemax} {
+		v.Activate(dst, src)
+		sum := 0.0
+		for _, p := range dst {
+			if p < 0 || math.IsNaN(p) {
+				t.Fatalf("%s produced %v", v, dst)
+			}
+			sum += p
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("%s sums to %v", v, sum)
+		}
+	}
+	Sparsemax.Activate(dst, []float64{2, 0.5, 0.1})
+	if dst[0] != 1 || dst[1] != 0 || dst[2] != 0 {
+		t.Errorf("Sparsemax should give a one-hot output, got %v", dst)
+	}
+}
+
+func TestSoftmaxClassifier(t *testing.T) {
+	gen := rand.New(rand.NewPCG(11, 12))
+	// three clusters on a line
+	var classes [][]mat.Vector
+	for i := 0; i < 30; i++ {
+		class := i % 3
+		x := float64(class)*2 - 2 + gen.NormFloat64()*0.2
+		target := mat.NewVecDense(3, nil)
+		target.SetVec(class, 1)
+		classes = append(classes, []mat.Vector{mat.NewVecDense(1, []float64{x}), target})
+	}
+	for _, test := range []struct {
+		head VectorActivation
+		loss Loss
+	}{
+		{Softmax, CrossEntropy},
+		{LogSoftmax, NLL},
+	} {
+		head := test.head
+		model := NewModel(rand.New(rand.NewPCG(13, 14)), Tanh, Linear(1), 1, 6, 3)
+		model.VectorOutput = &head
+		train := TrainingContext{Model: model, Loss: test.loss}
+		train.Train(classes, 300, 0.1, nil)
+		for _, sample := range classes {
+			out := train.Predict(sample[0])
+			if mat.Max(out) != out.AtVec(argmax(sample[1])) {
+				t.Errorf("%s misclassified %v: %v", head, sample[0].AtVec(0), mat.Formatted(out.T()))
+			}
+		}
+	}
+}
+
+func argmax(v mat.Vector) int {
+	best := 0
+	for i := 1; i < v.Len(); i++ {
+		if v.AtVec(i) > v.AtVec(best) {
+			best = i
+		}
+	}
+	return best
+}
+
+func TestVectorOutputEncoding(t *testing.T) {
+	model := NewModel(rand.New(rand.NewPCG(1, 1)), ReLU, Linear(1), 2, 3, 3)
+	model.VectorOutput = &Sparsemax
+	text, err := json.Marshal(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := model.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON, fromBinary Model
+	if err := json.Unmarshal(text, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := fromBinary.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, loaded := range []Model{fromJSON, fromBinary} {
+		if loaded.VectorOutput == nil || loaded.VectorOutput.Show() != "Sparsemax" {
+			t.Errorf("vector output not restored: %v", loaded.VectorOutput)
+		}
+	}
+}